@@ -0,0 +1,100 @@
+package euroxref
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache represents storage of retrieved exchange rate data which can be shared between clients,
+// e.g. backed by Redis so that only one of many processes downloads data from ECB within ttl.
+// Implementations have to be safe for concurrent use and must not modify stored data.
+type Cache interface {
+	// Get returns data stored under key unless it expired.
+	Get(key string) (*XRefRawResponse, bool)
+	// Set stores data under key for ttl.
+	Set(key string, data *XRefRawResponse, ttl time.Duration)
+}
+
+// memoryCacheEntry represents data stored in MemoryCache.
+type memoryCacheEntry struct {
+	data      *XRefRawResponse
+	expiresAt time.Time
+}
+
+// MemoryCache is in-memory implementation of Cache, useful for sharing data between clients of a single process.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+}
+
+// NewMemoryCache returns new empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]memoryCacheEntry)}
+}
+
+// Get returns data stored under key unless it expired.
+func (m *MemoryCache) Get(key string) (*XRefRawResponse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !time.Now().Before(entry.expiresAt) {
+		delete(m.entries, key)
+		return nil, false
+	}
+	return entry.data, true
+}
+
+// Set stores data under key for ttl, data with ttl of 0 or less isn't stored.
+func (m *MemoryCache) Set(key string, data *XRefRawResponse, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.entries == nil {
+		m.entries = make(map[string]memoryCacheEntry)
+	}
+	if ttl <= 0 {
+		delete(m.entries, key)
+		return
+	}
+	m.entries[key] = memoryCacheEntry{data: data, expiresAt: time.Now().Add(ttl)}
+}
+
+// cacheKey returns key under which data of the client is stored in Cache.
+func (c *Client) cacheKey() string {
+	key := c.SourceURL
+	if c.preferRevised {
+		key += "#revised"
+	}
+	if c.mergeDaily {
+		key += "#daily"
+	}
+	return key
+}
+
+// loadSharedCache populates client data from Cache, returning whether data was found.
+// c.mu has to be held by the caller.
+func (c *Client) loadSharedCache() bool {
+	if c.Cache == nil {
+		return false
+	}
+	data, ok := c.Cache.Get(c.cacheKey())
+	if !ok || data == nil {
+		return false
+	}
+	if data != c.XRefData {
+		c.XRefData = data
+		c.parsed = nil
+	}
+	c.lastFetched = time.Now()
+	c.cachedUntil = time.Time{}
+	return true
+}
+
+// storeSharedCache stores client data in Cache for cacheTTL. c.mu has to be held by the caller.
+func (c *Client) storeSharedCache() {
+	if ttl := c.cacheTTL(); c.Cache != nil && ttl > 0 {
+		c.Cache.Set(c.cacheKey(), c.XRefData, ttl)
+	}
+}
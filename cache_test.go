@@ -0,0 +1,91 @@
+package euroxref_test
+
+import (
+	"github.com/exaroth/euroxref-konrad"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache(t *testing.T) {
+	cache := euroxref.NewMemoryCache()
+	if _, ok := cache.Get("key"); ok {
+		t.Errorf("Want empty cache")
+	}
+	cache.Set("key", testResponse, time.Hour)
+	data, ok := cache.Get("key")
+	if !ok || data != testResponse {
+		t.Errorf("Values `%v` and `%v` are not equal", data, testResponse)
+	}
+	cache.Set("expired", testResponse, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, ok := cache.Get("expired"); ok {
+		t.Errorf("Want expired data not to be returned")
+	}
+	cache.Set("key", testResponse, 0)
+	if _, ok := cache.Get("key"); ok {
+		t.Errorf("Want data with zero ttl not to be stored")
+	}
+}
+
+func TestSharedCache(t *testing.T) {
+	cache := euroxref.NewMemoryCache()
+	requests := 0
+	hits := 0
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		client := euroxref.New(4, 60)
+		c := client.(*euroxref.Client)
+		c.Cache = cache
+		c.OnCacheHit = func() {
+			hits++
+		}
+		mock := MockServer(t, c, func(w http.ResponseWriter, req *http.Request) {
+			requests++
+			xmlHandle(testResponse)(w, req)
+		})
+		res, err := client.Convert(10, "CHF", "USD", date)
+		mock.Close()
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if res != 9.7282 {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, 9.7282, i)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Values `%v` and `%v` are not equal", requests, 1)
+	}
+	if hits != 2 {
+		t.Errorf("Values `%v` and `%v` are not equal", hits, 2)
+	}
+	if _, ok := cache.Get(euroxref.New(4, 60).(*euroxref.Client).SourceURL); !ok {
+		t.Errorf("Want data to be stored in cache")
+	}
+}
+
+func TestInvalidateSharedCache(t *testing.T) {
+	requests := 0
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	client := euroxref.New(4, 60)
+	client.(*euroxref.Client).Cache = euroxref.NewMemoryCache()
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		xmlHandle(testResponse)(w, req)
+	})
+	defer mock.Close()
+	for i := 0; i < 3; i++ {
+		// Data is downloaded again once after invalidation, even though it's still held by Cache.
+		if i == 1 {
+			if err := client.InvalidateCache(); err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			}
+		}
+		if _, err := client.Fetch(date); err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("Values `%v` and `%v` are not equal", requests, 2)
+	}
+}
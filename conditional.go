@@ -0,0 +1,41 @@
+package euroxref
+
+import (
+	"errors"
+	"net/http"
+)
+
+// errNotModified is returned by conditional requests when server reports data didn't change.
+var errNotModified = errors.New("Exchange rate data not modified")
+
+// feedValidators holds validators of a feed response used for conditional requests.
+type feedValidators struct {
+	// Value of ETag header of the response.
+	etag string
+	// Value of Last-Modified header of the response.
+	lastModified string
+}
+
+// conditionalValidators returns validators of the currently held data, or empty ones if they can't be used,
+// i.e. data wasn't downloaded from SourceURL as is. c.mu has to be held by the caller.
+func (c *Client) conditionalValidators() feedValidators {
+	if c.XRefData == nil || c.validated != c.XRefData {
+		return feedValidators{}
+	}
+	return c.validators
+}
+
+// setConditional adds headers to req which make server skip sending data that didn't change since v.
+func (v *feedValidators) setConditional(req *http.Request) {
+	if v.etag != "" {
+		req.Header.Set("If-None-Match", v.etag)
+	}
+	if v.lastModified != "" {
+		req.Header.Set("If-Modified-Since", v.lastModified)
+	}
+}
+
+// isSet checks if any of the validators is available.
+func (v *feedValidators) isSet() bool {
+	return v.etag != "" || v.lastModified != ""
+}
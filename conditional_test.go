@@ -0,0 +1,128 @@
+package euroxref_test
+
+import (
+	"github.com/exaroth/euroxref-konrad"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConditionalFetch(t *testing.T) {
+	tests := []struct {
+		ETag         string
+		LastModified string
+		Conditions   []string
+		Statuses     []int
+	}{
+		{
+			ETag:       `"v1"`,
+			Conditions: []string{"", `"v1"`, `"v1"`},
+			Statuses:   []int{http.StatusOK, http.StatusNotModified, http.StatusNotModified},
+		},
+		{
+			LastModified: "Fri, 11 Nov 2016 15:00:00 GMT",
+			Conditions:   []string{"", "Fri, 11 Nov 2016 15:00:00 GMT", "Fri, 11 Nov 2016 15:00:00 GMT"},
+			Statuses:     []int{http.StatusOK, http.StatusNotModified, http.StatusNotModified},
+		},
+		{
+			Conditions: []string{"", "", ""},
+			Statuses:   []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+	}
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	for i, test := range tests {
+		var conditions []string
+		var statuses []int
+		var doneErrs []error
+		client := euroxref.New(4, 0)
+		client.(*euroxref.Client).OnFetchDone = func(url string, dur time.Duration, err error) {
+			doneErrs = append(doneErrs, err)
+		}
+		mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+			condition := req.Header.Get("If-None-Match") + req.Header.Get("If-Modified-Since")
+			conditions = append(conditions, condition)
+			if condition != "" && (condition == test.ETag || condition == test.LastModified) {
+				statuses = append(statuses, http.StatusNotModified)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			if test.ETag != "" {
+				w.Header().Set("ETag", test.ETag)
+			}
+			if test.LastModified != "" {
+				w.Header().Set("Last-Modified", test.LastModified)
+			}
+			statuses = append(statuses, http.StatusOK)
+			xmlHandle(testResponse)(w, req)
+		})
+		var lastFetched time.Time
+		for range test.Conditions {
+			res, err := client.Fetch(date)
+			if err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			}
+			if len(res) != 4 {
+				t.Errorf("Want rates for 11th of November; got %v (i:%d)", res, i)
+			}
+			if fetched := client.LastFetched(); !fetched.After(lastFetched) {
+				t.Errorf("Want LastFetched to be bumped; got %v (i:%d)", fetched, i)
+			} else {
+				lastFetched = fetched
+			}
+		}
+		mock.Close()
+		if !reflect.DeepEqual(conditions, test.Conditions) {
+			t.Errorf("Values `%q` and `%q` are not equal (i:%d)", conditions, test.Conditions, i)
+		}
+		if !reflect.DeepEqual(statuses, test.Statuses) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", statuses, test.Statuses, i)
+		}
+		for _, err := range doneErrs {
+			if err != nil {
+				t.Errorf("Want OnFetchDone err == nil; got %v (i:%d)", err, i)
+			}
+		}
+	}
+}
+
+func TestConditionalFetchChangedData(t *testing.T) {
+	etag := `"v1"`
+	var conditions []string
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		conditions = append(conditions, req.Header.Get("If-None-Match"))
+		if req.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		if etag == `"v1"` {
+			xmlHandle(testResponse)(w, req)
+			return
+		}
+		xmlHandle(revisedResponse)(w, req)
+	})
+	defer mock.Close()
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	if _, err := client.Fetch(date); err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	etag = `"v2"`
+	res, err := client.Fetch(date)
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	if rate := res.Map()["USD"]; rate != 1.1 {
+		t.Errorf("Values `%v` and `%v` are not equal", rate, 1.1)
+	}
+	// Invalidated data is downloaded in full.
+	client.InvalidateCache()
+	if _, err := client.Fetch(date); err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	expected := []string{"", `"v1"`, ""}
+	if !reflect.DeepEqual(conditions, expected) {
+		t.Errorf("Values `%q` and `%q` are not equal", conditions, expected)
+	}
+}
@@ -0,0 +1,166 @@
+package euroxref
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvColumns is the number of columns expected in every row of ConvertCSV input.
+const csvColumns = 4
+
+// csvDay holds exchange rates fetched for a date while processing CSV input.
+type csvDay struct {
+	rates ExchangeRates
+	err   error
+}
+
+// ConvertCSV reads rows of (date, amount, source, target) from r, converts each of them and writes
+// (date, amount, source, target, result, error) rows to w. Rows which can't be converted are written
+// with empty result and error message instead of aborting. Exchange rates are fetched once per date.
+// If the first row starts with "date" column it's treated as header.
+func (c *Client) ConvertCSV(r io.Reader, w io.Writer) (err error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	writer := csv.NewWriter(w)
+	days := make(map[string]*csvDay)
+	var record []string
+	for line := 0; ; line++ {
+		record, err = reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return
+		}
+		if line == 0 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "date") {
+			err = writer.Write([]string{"date", "amount", "source", "target", "result", "error"})
+			if err != nil {
+				return
+			}
+			continue
+		}
+		res, convErr := c.convertCSVRecord(record, days)
+		out := make([]string, csvColumns)
+		copy(out, record)
+		if convErr != nil {
+			out = append(out, "", convErr.Error())
+		} else {
+			out = append(out, res, "")
+		}
+		err = writer.Write(out)
+		if err != nil {
+			return
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// convertCSVRecord converts single ConvertCSV row same as Convert, reusing exchange rates already fetched for its date.
+func (c *Client) convertCSVRecord(record []string, days map[string]*csvDay) (result string, err error) {
+	if len(record) != csvColumns {
+		return result, errors.New(fmt.Sprintf("Invalid row, expected %d columns, got %d", csvColumns, len(record)))
+	}
+	t, err := c.parseDate(strings.TrimSpace(record[0]))
+	if err != nil {
+		return
+	}
+	amount, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+	if err != nil {
+		return result, errors.New(fmt.Sprintf("Invalid amount: %s", record[1]))
+	}
+	codes, err := c.checkCurrencies(strings.TrimSpace(record[2]), strings.TrimSpace(record[3]))
+	if err != nil {
+		return
+	}
+	key := c.dateKey(t)
+	day, ok := days[key]
+	if !ok {
+		day = &csvDay{}
+		day.rates, day.err = c.Fetch(t)
+		days[key] = day
+	}
+	if day.err != nil {
+		return result, day.err
+	}
+	value, err := c.convertDay(day.rates, amount, codes[0], codes[1], t)
+	if err != nil {
+		return
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64), nil
+}
+
+// WriteCSV writes exchange rates to w as (currency, rate) rows preceded by a header row.
+func (e ExchangeRates) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"currency", "rate"})
+	for _, rec := range e {
+		writer.Write([]string{rec.Currency, strconv.FormatFloat(rec.Rate, 'f', -1, 64)})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ExportCSV writes exchange rates published between from and to (inclusive) to w as a table
+// with one row per date, sorted chronologically, and one column per currency, sorted by code.
+// Rates are formatted with client precision, cells of currencies not published on a given date are left empty.
+func (c *Client) ExportCSV(w io.Writer, from, to time.Time) (err error) {
+	fromKey, toKey := c.dateKey(from), c.dateKey(to)
+	if fromKey > toKey {
+		return errors.New(fmt.Sprintf("Invalid date range: %s is after %s", fromKey, toKey))
+	}
+	err = c.fetchXML(context.Background())
+	if err != nil {
+		return
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var keys []string
+	days := make(map[string]map[string]float64)
+	present := make(map[string]bool)
+	var rates ExchangeRates
+	for _, dayD := range c.XRefData.Data {
+		if dayD.RateTime < fromKey || dayD.RateTime > toKey || len(dayD.Rates) == 0 {
+			continue
+		}
+		rates, err = c.parseDay(dayD.RateTime)
+		if err != nil {
+			return
+		}
+		keys = append(keys, dayD.RateTime)
+		days[dayD.RateTime] = rates.Map()
+		for _, rec := range rates {
+			present[rec.Currency] = true
+		}
+	}
+	sort.Strings(keys)
+	var currencies []string
+	for currency := range present {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	writer := csv.NewWriter(w)
+	writer.Write(append([]string{"date"}, currencies...))
+	for _, key := range keys {
+		row := []string{key}
+		for _, currency := range currencies {
+			rate, ok := days[key][currency]
+			if !ok {
+				row = append(row, "")
+				continue
+			}
+			row = append(row, strconv.FormatFloat(c.round(rate), 'f', c.prec, 64))
+		}
+		writer.Write(row)
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,167 @@
+package euroxref_test
+
+import (
+	"bytes"
+	"github.com/exaroth/euroxref-konrad"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConvertCSV(t *testing.T) {
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		Input    string
+		Options  []euroxref.Option
+		Expected string
+		Err      bool
+	}{
+		{
+			Input: "date,amount,source,target\n" +
+				"2016-11-11,10,CHF,USD\n" +
+				"2016-11-10,10,USD,XYZ\n" +
+				"2016-11-11,10,EUR,EUR\n",
+			Expected: "date,amount,source,target,result,error\n" +
+				"2016-11-11,10,CHF,USD,9.7282,\n" +
+				"2016-11-10,10,USD,XYZ,19.9379,\n" +
+				"2016-11-11,10,EUR,EUR,10,\n",
+			Err: false,
+		},
+		{
+			Input: "2016-11-11,10,CHF,USD\n" +
+				"2016-11-11,10,BLE,USD\n" +
+				"2016-11-08,10,CHF,USD\n" +
+				"11/11/2016,10,CHF,USD\n" +
+				"2016-11-11,ten,CHF,USD\n" +
+				"2016-11-11,10,CHF\n" +
+				"2016-11-11,-10,CHF,USD\n",
+			Expected: "2016-11-11,10,CHF,USD,9.7282,\n" +
+				"2016-11-11,10,BLE,USD,,\"Invalid currencies selected: BLE, USD. List of available currency rates: USD, CHF, PLN, XYZ for 2016-11-11\"\n" +
+				"2016-11-08,10,CHF,USD,,Currency data for 2016-11-08 doesn't exist. No rates for this date are present in exchange rate data held by the client.\n" +
+				"11/11/2016,10,CHF,USD,,\"Invalid date: \"\"11/11/2016\"\", expected format 2006-01-02\"\n" +
+				"2016-11-11,ten,CHF,USD,,Invalid amount: ten\n" +
+				"2016-11-11,10,CHF,,,\"Invalid row, expected 4 columns, got 3\"\n" +
+				"2016-11-11,-10,CHF,USD,,Amount of conversion currency can't be negative\n",
+			Err: false,
+		},
+		{
+			// Rows are converted same as Convert, with dates resolved in client Location.
+			Input: "2016-11-11,10,CHF,USD\n" +
+				"2016-11-11,10,chf,usd\n" +
+				"2016-11-11,10,CHF,XYZ\n",
+			Options: []euroxref.Option{
+				euroxref.WithLocation(losAngeles),
+				func(c *euroxref.Client) {
+					c.UseCurrencyDecimals = true
+					c.StrictCurrencies = true
+				},
+			},
+			Expected: "2016-11-11,10,CHF,USD,9.73,\n" +
+				"2016-11-11,10,chf,usd,9.73,\n" +
+				"2016-11-11,10,CHF,XYZ,,\"Invalid ISO 4217 currency code: \"\"XYZ\"\"\"\n",
+			Err: false,
+		},
+		{
+			Input: "2016-11-11,\"10,CHF,USD\n",
+			Err:   true,
+		},
+	}
+	for i, test := range tests {
+		requests := 0
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0, test.Options...)
+		mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+			requests++
+			handler(w, req)
+		})
+		defer mock.Close()
+		out := &bytes.Buffer{}
+		err := client.ConvertCSV(strings.NewReader(test.Input), out)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if test.Expected != out.String() {
+			t.Errorf("Values `%s` and `%s` are not equal (i:%d)", test.Expected, out.String(), i)
+		}
+		if requests > 2 {
+			t.Errorf("Want data fetched at most once per date; got %d requests (i:%d)", requests, i)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	rates := euroxref.ExchangeRates{
+		{Currency: "USD", Rate: 1.002},
+		{Currency: "PLN", Rate: 4.5},
+	}
+	var out bytes.Buffer
+	if err := rates.WriteCSV(&out); err != nil {
+		t.Errorf("Want err == nil; got %v", err)
+	}
+	expected := "currency,rate\nUSD,1.002\nPLN,4.5\n"
+	if out.String() != expected {
+		t.Errorf("Values `%v` and `%v` are not equal", out.String(), expected)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	tests := []struct {
+		From     time.Time
+		To       time.Time
+		Expected string
+		Err      bool
+	}{
+		{
+			From: time.Date(2016, time.November, 8, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Expected: "date,CHF,PLN,USD,XYZ\n" +
+				"2016-11-09,,,3.0000,\n" +
+				"2016-11-10,,0.3211,1.0031,2.0000\n" +
+				"2016-11-11,1.0300,0.3210,1.0020,2.0000\n",
+			Err: false,
+		},
+		{
+			From:     time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+			Expected: "date,USD\n2016-11-09,3.0000\n",
+			Err:      false,
+		},
+		{
+			From: time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+			Err:  true,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0)
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		var out bytes.Buffer
+		err := client.ExportCSV(&out, test.From, test.To)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if out.String() != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", out.String(), test.Expected, i)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package euroxref
+
+import (
+	"context"
+	"sort"
+)
+
+// currencyInfo holds descriptive metadata of a currency.
+type currencyInfo struct {
+	Name   string
+	Symbol string
+}
+
+// currencyTable contains metadata for currencies published by European Central Bank.
+var currencyTable = map[string]currencyInfo{
+	"AUD": {Name: "Australian dollar", Symbol: "A$"},
+	"BGN": {Name: "Bulgarian lev", Symbol: "лв"},
+	"BRL": {Name: "Brazilian real", Symbol: "R$"},
+	"CAD": {Name: "Canadian dollar", Symbol: "C$"},
+	"CHF": {Name: "Swiss franc", Symbol: "CHF"},
+	"CNY": {Name: "Chinese yuan renminbi", Symbol: "¥"},
+	"CYP": {Name: "Cyprus pound", Symbol: "£"},
+	"CZK": {Name: "Czech koruna", Symbol: "Kč"},
+	"DKK": {Name: "Danish krone", Symbol: "kr"},
+	"EEK": {Name: "Estonian kroon", Symbol: "kr"},
+	"EUR": {Name: "Euro", Symbol: "€"},
+	"GBP": {Name: "Pound sterling", Symbol: "£"},
+	"HKD": {Name: "Hong Kong dollar", Symbol: "HK$"},
+	"HRK": {Name: "Croatian kuna", Symbol: "kn"},
+	"HUF": {Name: "Hungarian forint", Symbol: "Ft"},
+	"IDR": {Name: "Indonesian rupiah", Symbol: "Rp"},
+	"ILS": {Name: "Israeli shekel", Symbol: "₪"},
+	"INR": {Name: "Indian rupee", Symbol: "₹"},
+	"ISK": {Name: "Icelandic krona", Symbol: "kr"},
+	"JPY": {Name: "Japanese yen", Symbol: "¥"},
+	"KRW": {Name: "South Korean won", Symbol: "₩"},
+	"LTL": {Name: "Lithuanian litas", Symbol: "Lt"},
+	"LVL": {Name: "Latvian lats", Symbol: "Ls"},
+	"MTL": {Name: "Maltese lira", Symbol: "₤"},
+	"MXN": {Name: "Mexican peso", Symbol: "Mex$"},
+	"MYR": {Name: "Malaysian ringgit", Symbol: "RM"},
+	"NOK": {Name: "Norwegian krone", Symbol: "kr"},
+	"NZD": {Name: "New Zealand dollar", Symbol: "NZ$"},
+	"PHP": {Name: "Philippine peso", Symbol: "₱"},
+	"PLN": {Name: "Polish zloty", Symbol: "zł"},
+	"ROL": {Name: "Romanian leu (old)", Symbol: "L"},
+	"RON": {Name: "Romanian leu", Symbol: "lei"},
+	"RUB": {Name: "Russian rouble", Symbol: "₽"},
+	"SEK": {Name: "Swedish krona", Symbol: "kr"},
+	"SGD": {Name: "Singapore dollar", Symbol: "S$"},
+	"SIT": {Name: "Slovenian tolar", Symbol: "SIT"},
+	"SKK": {Name: "Slovak koruna", Symbol: "Sk"},
+	"THB": {Name: "Thai baht", Symbol: "฿"},
+	"TRL": {Name: "Turkish lira (old)", Symbol: "TL"},
+	"TRY": {Name: "Turkish lira", Symbol: "₺"},
+	"USD": {Name: "US dollar", Symbol: "$"},
+	"ZAR": {Name: "South African rand", Symbol: "R"},
+}
+
+// CurrencyMeta describes a currency along with its availability in the latest exchange rate data.
+type CurrencyMeta struct {
+	// ISO 4217 currency code.
+	Code string `json:"code"`
+	// Name of the currency, empty if unknown.
+	Name string `json:"name"`
+	// Symbol of the currency, empty if unknown.
+	Symbol string `json:"symbol"`
+	// Whether currency has a rate published in the latest available data.
+	Present bool `json:"present"`
+}
+
+// CurrencyCatalog returns metadata of all known currencies sorted by code,
+// along with any currencies found in the latest data which have no metadata available.
+func (c *Client) CurrencyCatalog() (catalog []CurrencyMeta, err error) {
+	err = c.fetchXML(context.Background())
+	if err != nil {
+		return
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	present := make(map[string]bool)
+	var latest *XRefRawData
+	for idx, dayD := range c.XRefData.Data {
+		if len(dayD.Rates) > 0 && (latest == nil || dayD.RateTime > latest.RateTime) {
+			latest = &c.XRefData.Data[idx]
+		}
+	}
+	if latest != nil {
+		present[EUCurr] = true
+		for _, rec := range latest.Rates {
+			present[rec.Currency] = true
+		}
+	}
+	for code, info := range currencyTable {
+		catalog = append(catalog, CurrencyMeta{
+			Code:    code,
+			Name:    info.Name,
+			Symbol:  info.Symbol,
+			Present: present[code],
+		})
+	}
+	for code := range present {
+		if _, ok := currencyTable[code]; !ok {
+			catalog = append(catalog, CurrencyMeta{Code: code, Present: true})
+		}
+	}
+	sort.Slice(catalog, func(i, j int) bool {
+		return catalog[i].Code < catalog[j].Code
+	})
+	return
+}
@@ -0,0 +1,59 @@
+package euroxref_test
+
+import (
+	"encoding/json"
+	"github.com/exaroth/euroxref-konrad"
+	"testing"
+)
+
+func TestCurrencyCatalog(t *testing.T) {
+	var reqUrl, reqMethod, reqBody string
+	handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), handler)
+	defer mock.Close()
+	catalog, err := client.CurrencyCatalog()
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	byCode := make(map[string]euroxref.CurrencyMeta)
+	for i, meta := range catalog {
+		if i > 0 && catalog[i-1].Code >= meta.Code {
+			t.Errorf("Catalog not sorted at %s", meta.Code)
+		}
+		byCode[meta.Code] = meta
+	}
+	tests := []struct {
+		Code     string
+		Expected euroxref.CurrencyMeta
+	}{
+		{
+			Code:     "USD",
+			Expected: euroxref.CurrencyMeta{Code: "USD", Name: "US dollar", Symbol: "$", Present: true},
+		},
+		{
+			Code:     "EUR",
+			Expected: euroxref.CurrencyMeta{Code: "EUR", Name: "Euro", Symbol: "€", Present: true},
+		},
+		{
+			Code:     "JPY",
+			Expected: euroxref.CurrencyMeta{Code: "JPY", Name: "Japanese yen", Symbol: "¥", Present: false},
+		},
+		{
+			Code:     "XYZ",
+			Expected: euroxref.CurrencyMeta{Code: "XYZ", Present: true},
+		},
+	}
+	for i, test := range tests {
+		if byCode[test.Code] != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.Expected, byCode[test.Code], i)
+		}
+	}
+	data, err := json.Marshal(byCode["USD"])
+	if err != nil {
+		t.Errorf("Want err == nil; got %v", err)
+	}
+	if expected := `{"code":"USD","name":"US dollar","symbol":"$","present":true}`; string(data) != expected {
+		t.Errorf("Values `%s` and `%s` are not equal", expected, data)
+	}
+}
@@ -0,0 +1,66 @@
+package euroxref
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WithLocation sets location in which requested times are interpreted before resolving ECB date.
+// ECB publishes rates in Frankfurt, so CET avoids times near midnight in distant zones resolving to a different ECB day.
+func WithLocation(loc *time.Location) Option {
+	return func(c *Client) {
+		c.Location = loc
+	}
+}
+
+// ResolveDate returns ECB date (midnight UTC) for which exchange rates are looked up when t is requested.
+// If Location is set t is converted to it first, shifted reports whether that resolved
+// to a different date than the calendar date of t in its own location.
+func (c *Client) ResolveDate(t time.Time) (date time.Time, shifted bool) {
+	naive := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if c.Location == nil {
+		return naive, false
+	}
+	lt := t.In(c.Location)
+	date = time.Date(lt.Year(), lt.Month(), lt.Day(), 0, 0, 0, 0, time.UTC)
+	return date, !date.Equal(naive)
+}
+
+// dateKey returns key identifying day record in ECB data for given time.
+func (c *Client) dateKey(t time.Time) string {
+	date, _ := c.ResolveDate(t)
+	return date.Format(XRefDateLayout)
+}
+
+// ConvertAt converts amount same as Convert for date given as string in XRefDateLayout, e.g. "2016-11-11".
+func (c *Client) ConvertAt(amount float64, source, target, dateStr string) (result float64, err error) {
+	t, err := c.parseDate(dateStr)
+	if err != nil {
+		return
+	}
+	return c.Convert(amount, source, target, t)
+}
+
+// FetchAt retrieves exchange rates same as Fetch for date given as string in XRefDateLayout, e.g. "2016-11-11".
+func (c *Client) FetchAt(dateStr string) (rates ExchangeRates, err error) {
+	t, err := c.parseDate(dateStr)
+	if err != nil {
+		return
+	}
+	return c.Fetch(t)
+}
+
+// parseDate parses date in XRefDateLayout as midnight in client Location,
+// so that it resolves to the same ECB date regardless of the Location.
+func (c *Client) parseDate(dateStr string) (t time.Time, err error) {
+	loc := c.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t, err = time.ParseInLocation(XRefDateLayout, dateStr, loc)
+	if err != nil {
+		return t, errors.New(fmt.Sprintf("Invalid date: %q, expected format %s", dateStr, XRefDateLayout))
+	}
+	return
+}
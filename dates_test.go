@@ -0,0 +1,191 @@
+package euroxref_test
+
+import (
+	"github.com/exaroth/euroxref-konrad"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResolveDate(t *testing.T) {
+	cet := time.FixedZone("CET", 60*60)
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	tests := []struct {
+		Time     time.Time
+		Location *time.Location
+		Expected time.Time
+		Shifted  bool
+	}{
+		{
+			Time:     time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC),
+			Location: nil,
+			Expected: time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Shifted:  false,
+		},
+		{
+			Time:     time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC),
+			Location: time.UTC,
+			Expected: time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Shifted:  false,
+		},
+		{
+			Time:     time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC),
+			Location: cet,
+			Expected: time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Shifted:  true,
+		},
+		{
+			Time:     time.Date(2016, time.November, 10, 22, 59, 59, 0, time.UTC),
+			Location: cet,
+			Expected: time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Shifted:  false,
+		},
+		{
+			Time:     time.Date(2016, time.November, 11, 0, 30, 0, 0, cet),
+			Location: time.UTC,
+			Expected: time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Shifted:  true,
+		},
+		{
+			Time:     time.Date(2016, time.November, 10, 23, 0, 0, 0, la),
+			Location: nil,
+			Expected: time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Shifted:  false,
+		},
+		{
+			Time:     time.Date(2016, time.November, 10, 23, 0, 0, 0, la),
+			Location: cet,
+			Expected: time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Shifted:  true,
+		},
+	}
+	for i, test := range tests {
+		client := euroxref.New(4, 0, euroxref.WithLocation(test.Location))
+		date, shifted := client.ResolveDate(test.Time)
+		if !test.Expected.Equal(date) || test.Shifted != shifted {
+			t.Errorf("Want %v (shifted: %v); got %v (shifted: %v) (i:%d)", test.Expected, test.Shifted, date, shifted, i)
+		}
+	}
+}
+
+func TestFetchWithLocation(t *testing.T) {
+	var reqUrl, reqMethod, reqBody string
+	handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+	client := euroxref.New(4, 0, euroxref.WithLocation(time.FixedZone("CET", 60*60)))
+	mock := MockServer(t, client.(*euroxref.Client), handler)
+	defer mock.Close()
+	// 23:00 UTC on 10th of November is already 11th of November in CET.
+	res, err := client.Fetch(time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	if len(res) != 4 {
+		t.Errorf("Want rates for 11th of November; got %v", res)
+	}
+}
+
+func TestFetchWithLocationLosAngeles(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	tests := []struct {
+		Location *time.Location
+		Expected euroxref.ExchangeRates
+	}{
+		{
+			// Without location calendar date in Los Angeles is used.
+			Location: nil,
+			Expected: euroxref.ExchangeRates{
+				{Currency: "USD", Rate: 1.003123142},
+				{Currency: "PLN", Rate: 0.3211231231},
+				{Currency: "XYZ", Rate: 2.00001999},
+			},
+		},
+		{
+			// 23:00 in Los Angeles on 10th of November is already 11th of November in Frankfurt.
+			Location: time.FixedZone("CET", 60*60),
+			Expected: euroxref.ExchangeRates{
+				{Currency: "USD", Rate: 1.002},
+				{Currency: "CHF", Rate: 1.03},
+				{Currency: "PLN", Rate: 0.321},
+				{Currency: "XYZ", Rate: 1.9999999},
+			},
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0, euroxref.WithLocation(test.Location))
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		res, err := client.Fetch(time.Date(2016, time.November, 10, 23, 0, 0, 0, la))
+		mock.Close()
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(res, test.Expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+}
+
+func TestConvertAt(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	tests := []struct {
+		Date     string
+		Location *time.Location
+		Expected float64
+		Err      bool
+	}{
+		{Date: "2016-11-11", Expected: 9.7282, Err: false},
+		{Date: "2016-11-11", Location: la, Expected: 9.7282, Err: false},
+		{Date: "2016-11-10", Err: true},
+		{Date: "11/11/2016", Err: true},
+		{Date: "", Err: true},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0, euroxref.WithLocation(test.Location))
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		res, err := client.ConvertAt(10, "CHF", "USD", test.Date)
+		mock.Close()
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if res != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+}
+
+func TestFetchAt(t *testing.T) {
+	var reqUrl, reqMethod, reqBody string
+	handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+	client := euroxref.New(4, 60)
+	mock := MockServer(t, client.(*euroxref.Client), handler)
+	defer mock.Close()
+	res, err := client.FetchAt("2016-11-09")
+	if err != nil {
+		t.Errorf("Want err == nil; got %v", err)
+	}
+	expected := euroxref.ExchangeRates{{Currency: "USD", Rate: 2.999999}}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("Values `%v` and `%v` are not equal", res, expected)
+	}
+	if _, err := client.FetchAt("2016-13-01"); err == nil {
+		t.Errorf("Want err != nil; got nil")
+	}
+}
@@ -0,0 +1,106 @@
+package euroxref
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ratFromFloat converts float into exact rational number using its shortest decimal representation,
+// so that e.g. 0.1 is treated as 1/10 rather than its binary approximation.
+func ratFromFloat(num float64) (*big.Rat, bool) {
+	return new(big.Rat).SetString(strconv.FormatFloat(num, 'g', -1, 64))
+}
+
+// FormatRate formats value as decimal string with exactly prec digits after the decimal point,
+// rounded half away from zero same as FloatToFixed. Unlike float results, which can't faithfully
+// hold more than about 15 significant digits, the string is exact at any precision: value is taken
+// at its shortest decimal representation, so additional digits are zeros rather than binary noise.
+// Negative precision is treated as 0, NaN and infinities are formatted as by strconv.
+func FormatRate(value float64, prec int) string {
+	if prec < 0 {
+		prec = 0
+	}
+	exact, ok := ratFromFloat(value)
+	if !ok {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return roundRat(exact, prec, HalfUp).FloatString(prec)
+}
+
+// parseDecimal parses decimal number such as "-1234.56" into exact rational number.
+func parseDecimal(num string) (*big.Rat, error) {
+	num = strings.TrimSpace(num)
+	digits := strings.TrimLeft(num, "+-")
+	valid := len(num)-len(digits) <= 1 && strings.Count(digits, ".") <= 1 && strings.Trim(digits, ".") != ""
+	for _, r := range digits {
+		if (r < '0' || r > '9') && r != '.' {
+			valid = false
+		}
+	}
+	res, ok := new(big.Rat).SetString(num)
+	if !valid || !ok {
+		return nil, errors.New(fmt.Sprintf("Invalid decimal number: %q", num))
+	}
+	return res, nil
+}
+
+// roundRat rounds rational number to prec decimal digits using given rounding mode.
+func roundRat(num *big.Rat, prec int, mode RoundingMode) *big.Rat {
+	exp := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(prec)), nil)
+	scaled := new(big.Rat).Mul(num, new(big.Rat).SetInt(exp))
+	// Quotient is truncated towards zero, remainder has sign of the number.
+	quo, rem := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	sign := big.NewInt(int64(scaled.Num().Sign()))
+	half := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(scaled.Denom())
+	switch mode {
+	case HalfEven:
+		if half > 0 || (half == 0 && quo.Bit(0) == 1) {
+			quo.Add(quo, sign)
+		}
+	case Floor:
+		if rem.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		}
+	case Ceil:
+		if rem.Sign() > 0 {
+			quo.Add(quo, big.NewInt(1))
+		}
+	case Truncate:
+	default:
+		if half >= 0 {
+			quo.Add(quo, sign)
+		}
+	}
+	return new(big.Rat).SetFrac(quo, exp)
+}
+
+// crossRateRat returns exact rate of target currency per unit of source currency.
+func crossRateRat(in, to *ExchangeRate) (*big.Rat, error) {
+	x, okX := ratFromFloat(to.Rate)
+	y, okY := ratFromFloat(in.Rate)
+	if !okX || !okY || y.Sign() == 0 {
+		return nil, errors.New(fmt.Sprintf("Invalid exchange rate between %s and %s", in.Currency, to.Currency))
+	}
+	return x.Quo(x, y), nil
+}
+
+// ratToFixed rounds rational number to prec digits (at least one) and converts it to float.
+func ratToFixed(num *big.Rat, prec int, mode RoundingMode) float64 {
+	if prec < 1 {
+		prec = 1
+	}
+	res, _ := roundRat(num, prec, mode).Float64()
+	return res
+}
+
+// exceedsFloatPrecision checks if num can't be rounded to prec digits using float64 arithmetic.
+func exceedsFloatPrecision(num float64, prec int) bool {
+	if prec < 1 {
+		prec = 1
+	}
+	return math.Abs(num)*math.Pow(10, float64(prec)) >= maxExactFloat
+}
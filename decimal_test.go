@@ -0,0 +1,306 @@
+package euroxref_test
+
+import (
+	"github.com/exaroth/euroxref-konrad"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var decimalResponse = &euroxref.XRefRawResponse{
+	Data: []euroxref.XRefRawData{
+		{
+			RateTime: "2016-11-11",
+			Rates: []euroxref.RawExchangeRate{
+				{
+					Currency: "USD",
+					Rate:     "1.1",
+				},
+				{
+					Currency: "GBP",
+					Rate:     "1.1",
+				},
+				{
+					Currency: "JPY",
+					Rate:     "0.3",
+				},
+				{
+					Currency: "SEK",
+					Rate:     "9.7391",
+				},
+			},
+		},
+	},
+}
+
+func TestDecimalConversion(t *testing.T) {
+	tests := []struct {
+		Amount   float64
+		Source   string
+		Target   string
+		Prec     uint
+		Expected float64
+	}{
+		{
+			Amount:   1.005,
+			Source:   "USD",
+			Target:   "GBP",
+			Prec:     4,
+			Expected: 1.01,
+		},
+		{
+			Amount:   3,
+			Source:   "USD",
+			Target:   "JPY",
+			Prec:     4,
+			Expected: 0.8182,
+		},
+		{
+			Amount:   0.7,
+			Source:   "EUR",
+			Target:   "SEK",
+			Prec:     2,
+			Expected: 6.82,
+		},
+		{
+			Amount:   10021000000.8999999,
+			Source:   "USD",
+			Target:   "GBP",
+			Prec:     4,
+			Expected: 10021000000.9,
+		},
+	}
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	for i, test := range tests {
+		client := euroxref.New(test.Prec, 0)
+		mock := MockServer(t, client.(*euroxref.Client), xmlHandle(decimalResponse))
+		res, err := client.Convert(test.Amount, test.Source, test.Target, date)
+		mock.Close()
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			continue
+		}
+		if res != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+}
+
+func TestConvertString(t *testing.T) {
+	tests := []struct {
+		Amount   string
+		Source   string
+		Target   string
+		Prec     uint
+		Expected string
+		Err      bool
+	}{
+		{
+			Amount:   "1.005",
+			Source:   "USD",
+			Target:   "GBP",
+			Prec:     4,
+			Expected: "1.0100",
+			Err:      false,
+		},
+		{
+			Amount:   "3",
+			Source:   "USD",
+			Target:   "JPY",
+			Prec:     4,
+			Expected: "0.8182",
+			Err:      false,
+		},
+		{
+			Amount:   " 0.7 ",
+			Source:   "eur",
+			Target:   "SEK",
+			Prec:     2,
+			Expected: "6.82",
+			Err:      false,
+		},
+		{
+			Amount:   "123456789012345678.91",
+			Source:   "EUR",
+			Target:   "USD",
+			Prec:     2,
+			Expected: "135802467913580246.80",
+			Err:      false,
+		},
+		{
+			Amount:   "10.555",
+			Source:   "USD",
+			Target:   "USD",
+			Prec:     2,
+			Expected: "10.56",
+			Err:      false,
+		},
+		{
+			Amount: "-5",
+			Source: "USD",
+			Target: "GBP",
+			Prec:   2,
+			Err:    true,
+		},
+		{
+			Amount: "1/3",
+			Source: "USD",
+			Target: "GBP",
+			Prec:   2,
+			Err:    true,
+		},
+		{
+			Amount: "1e3",
+			Source: "USD",
+			Target: "GBP",
+			Prec:   2,
+			Err:    true,
+		},
+		{
+			Amount: "",
+			Source: "USD",
+			Target: "GBP",
+			Prec:   2,
+			Err:    true,
+		},
+		{
+			Amount: "10",
+			Source: "USD",
+			Target: "BLE",
+			Prec:   2,
+			Err:    true,
+		},
+	}
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	for i, test := range tests {
+		client := euroxref.New(test.Prec, 0)
+		mock := MockServer(t, client.(*euroxref.Client), xmlHandle(decimalResponse))
+		res, err := client.ConvertString(test.Amount, test.Source, test.Target, date)
+		mock.Close()
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			continue
+		}
+		if res != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+}
+
+func TestFormatRate(t *testing.T) {
+	tests := []struct {
+		Value    float64
+		Prec     int
+		Expected string
+	}{
+		{Value: 1.005, Prec: 2, Expected: "1.01"},
+		{Value: 2.5, Prec: 0, Expected: "3"},
+		{Value: -1.25, Prec: 1, Expected: "-1.3"},
+		{Value: 1.1, Prec: 10, Expected: "1.1000000000"},
+		{Value: 0.1, Prec: 20, Expected: "0.10000000000000000000"},
+		{Value: 12345678901234.5678, Prec: 4, Expected: "12345678901234.5680"},
+		{Value: 7, Prec: -1, Expected: "7"},
+		{Value: math.NaN(), Prec: 2, Expected: "NaN"},
+	}
+	for i, test := range tests {
+		if res := euroxref.FormatRate(test.Value, test.Prec); res != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+	// String form has to match rounded float wherever float64 is able to hold the result.
+	for _, value := range []float64{1.005, 0.125, 9.72815533, 2.999999, -3.14159265, 1234.5678} {
+		for prec := 1; prec <= 8; prec++ {
+			want := strconv.FormatFloat(euroxref.FloatToFixed(value, prec), 'f', prec, 64)
+			if res := euroxref.FormatRate(value, prec); res != want {
+				t.Errorf("Values `%v` and `%v` are not equal (%v, %d)", res, want, value, prec)
+			}
+		}
+	}
+}
+
+func TestConvertFormatted(t *testing.T) {
+	tests := []struct {
+		Amount   float64
+		Source   string
+		Target   string
+		Prec     uint
+		Expected string
+		Err      bool
+	}{
+		{
+			Amount:   3,
+			Source:   "USD",
+			Target:   "JPY",
+			Prec:     4,
+			Expected: "0.8182",
+			Err:      false,
+		},
+		{
+			Amount:   3,
+			Source:   "USD",
+			Target:   "JPY",
+			Prec:     10,
+			Expected: "0.8181818182",
+			Err:      false,
+		},
+		{
+			Amount:   10021000000.9,
+			Source:   "USD",
+			Target:   "SEK",
+			Prec:     10,
+			Expected: "88723201007.9683545455",
+			Err:      false,
+		},
+		{
+			Amount: math.Inf(1),
+			Source: "USD",
+			Target: "SEK",
+			Prec:   2,
+			Err:    true,
+		},
+		{
+			Amount: -1,
+			Source: "USD",
+			Target: "SEK",
+			Prec:   2,
+			Err:    true,
+		},
+	}
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	for i, test := range tests {
+		client := euroxref.New(test.Prec, 60)
+		mock := MockServer(t, client.(*euroxref.Client), xmlHandle(decimalResponse))
+		res, err := client.ConvertFormatted(test.Amount, test.Source, test.Target, date)
+		if test.Err {
+			mock.Close()
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if res != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+		// Float result has to match the string whenever float64 can represent it.
+		if test.Prec <= 4 {
+			f, err := client.Convert(test.Amount, test.Source, test.Target, date)
+			if err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			}
+			if s := strconv.FormatFloat(f, 'f', int(test.Prec), 64); s != res {
+				t.Errorf("Values `%v` and `%v` are not equal (i:%d)", s, res, i)
+			}
+		}
+		mock.Close()
+	}
+}
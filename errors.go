@@ -0,0 +1,133 @@
+package euroxref
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrClientClosed is returned when data is requested from a client after calling Close.
+var ErrClientClosed = errors.New("Client is closed")
+
+// ValidationError is returned when parameter passed to the client is outside of supported range.
+type ValidationError struct {
+	// Name of the invalid parameter.
+	Param string
+	// Value which failed validation.
+	Value interface{}
+	// Reason why value was rejected.
+	Reason string
+}
+
+// Error implements error interface.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("Invalid value for %s: %v, %s", e.Param, e.Value, e.Reason)
+}
+
+// ErrDateNotFound is returned when there's no exchange rate data for requested date.
+type ErrDateNotFound struct {
+	// Date for which data was requested.
+	Date time.Time
+}
+
+// Error implements error interface.
+func (e *ErrDateNotFound) Error() string {
+	// Dates covered depend on the source of the data, so they aren't assumed here.
+	return fmt.Sprintf("Currency data for %s doesn't exist. No rates for this date are present in exchange rate data held by the client.", e.Date.Format(XRefDateLayout))
+}
+
+// ErrCurrencyNotFound is returned when requested currencies have no exchange rate published for a date.
+type ErrCurrencyNotFound struct {
+	// Requested currencies.
+	Currencies []string
+	// Currencies with rates available for the date.
+	Available []string
+	// Date for which rates were requested.
+	Date time.Time
+}
+
+// Error implements error interface.
+func (e *ErrCurrencyNotFound) Error() string {
+	return fmt.Sprintf("Invalid currencies selected: %s. List of available currency rates: %s for %s", strings.Join(e.Currencies, ", "), strings.Join(e.Available, ", "), e.Date.Format(XRefDateLayout))
+}
+
+// ErrInvalidCurrencyCode is returned in StrictCurrencies mode for codes which aren't defined by ISO 4217.
+type ErrInvalidCurrencyCode struct {
+	// Rejected currency code.
+	Code string
+}
+
+// Error implements error interface.
+func (e *ErrInvalidCurrencyCode) Error() string {
+	return fmt.Sprintf("Invalid ISO 4217 currency code: %q", e.Code)
+}
+
+// StatusError is returned when server responds with status other than 200 OK.
+type StatusError struct {
+	// Url of the requested feed.
+	URL string
+	// Status code of the response.
+	StatusCode int
+	// Status line of the response, e.g. "503 Service Unavailable".
+	Status string
+	// Beginning of the response body, useful for identifying error pages returned by proxies.
+	Body string
+}
+
+// Error implements error interface.
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("Unexpected response status for %s: %s", e.URL, e.Status)
+	}
+	return fmt.Sprintf("Unexpected response status for %s: %s, response body: %q", e.URL, e.Status, e.Body)
+}
+
+// TargetsError is returned when conversion to some of the requested target currencies failed.
+type TargetsError struct {
+	// Errors keyed by target currency.
+	Targets map[string]error
+}
+
+// Error implements error interface.
+func (e *TargetsError) Error() string {
+	var targets []string
+	for target := range e.Targets {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	var reasons []string
+	for _, target := range targets {
+		reasons = append(reasons, fmt.Sprintf("%s (%s)", target, e.Targets[target]))
+	}
+	return fmt.Sprintf("Conversion failed for target currencies: %s", strings.Join(reasons, ", "))
+}
+
+// ErrStopStream can be returned by handler passed to FetchStream to stop retrieving further records without an error.
+var ErrStopStream = errors.New("Stream stopped")
+
+// ParseError is returned when exchange rate published by ECB can't be parsed.
+type ParseError struct {
+	// Date of the record holding the rate in XRefDateLayout, empty if unknown.
+	RateTime string
+	// Currency of the rate.
+	Currency string
+	// Raw value which couldn't be parsed.
+	Value string
+	// Underlying parsing error.
+	Err error
+}
+
+// Error implements error interface.
+func (e *ParseError) Error() string {
+	if e.RateTime == "" {
+		return fmt.Sprintf("Invalid input rate value for %s, %s", e.Currency, e.Value)
+	}
+	return fmt.Sprintf("Invalid input rate value for %s, %s on %s", e.Currency, e.Value, e.RateTime)
+}
+
+// Unwrap returns underlying parsing error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
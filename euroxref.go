@@ -2,19 +2,31 @@
 package euroxref
 
 import (
+	"context"
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"math"
+	"math/big"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 // exchangeReferenceRatesUrl defines source url for currency data.
 const exchangeReferenceRatesUrl = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist-90d.xml"
 
+// historicalReferenceRatesUrl defines source url for complete history of currency data.
+const historicalReferenceRatesUrl = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist.xml"
+
+// dailyReferenceRatesUrl defines source url for currency data of the latest business day.
+const dailyReferenceRatesUrl = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+
 // EUCurr is identifier for Euro currency.
 const EUCurr = "EUR"
 
@@ -24,6 +36,28 @@ const EURate = 1.0
 // XRefDateLayout represents date format used by European Central Bank for referencing dates in xml file.
 const XRefDateLayout = "2006-01-02"
 
+// MaxPrecision is the highest precision supported, float64 can't reliably represent more decimal digits.
+const MaxPrecision = 15
+
+// MaxRefreshInterval is the longest refresh interval (in seconds) accepted by NewValidated.
+const MaxRefreshInterval = 7 * 24 * 60 * 60
+
+// DefaultAmountPrecision is precision to which amounts are rounded before conversion,
+// by default amounts are rounded to cents.
+const DefaultAmountPrecision = 2
+
+// statusErrorBodyLimit is number of bytes of response body included in StatusError.
+const statusErrorBodyLimit = 512
+
+// DefaultTimeout is time limit for retrieving exchange rate data used by clients created with New.
+const DefaultTimeout = 30 * time.Second
+
+// neverExpires is used as expiry of data which is never retrieved again, e.g. loaded with LoadFrom.
+var neverExpires = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// maxExactFloat is the magnitude up to which float64 represents every integer exactly.
+const maxExactFloat = 1 << 53
+
 // RawExchangeRate represents single currency record retrieved from European Cental Bank XML file.
 type RawExchangeRate struct {
 	Currency string `xml:"currency,attr"`
@@ -37,6 +71,10 @@ type XRefRawData struct {
 }
 
 // XRefRawResponse represents exchange rate data retrieved from European Central Bank.
+// Elements and attributes are matched by their local names regardless of namespace, so data is decoded
+// the same whether it uses gesmes and eurofxref namespaces as published by ECB, prefixes them or omits them.
+// If data lists the same date more than once (e.g. concatenated files), only the last occurrence is kept
+// when it's retrieved or loaded, so all methods of the client agree on rates of that date.
 type XRefRawResponse struct {
 	XMLName xml.Name
 	Data    []XRefRawData `xml:"Cube>Cube"`
@@ -50,9 +88,9 @@ type ExchangeRateInterface interface {
 // exchangeRate represents parsed RawExchangeRate to be passed for processing.
 type ExchangeRate struct {
 	// Currency representation.
-	Currency string
+	Currency string `json:"currency"`
 	// Rate for given day.
-	Rate float64
+	Rate float64 `json:"rate"`
 }
 
 // Rounds Rate of given Currency based on precision given.
@@ -67,19 +105,55 @@ type ExchangeRates []ExchangeRate
 
 // Map converts collection of exchangeRate structs into more readable format.
 func (e ExchangeRates) Map() map[string]float64 {
-	res := make(map[string]float64)
+	res := make(map[string]float64, len(e))
 	for _, v := range e {
 		res[v.Currency] = v.Rate
 	}
 	return res
 }
 
+// MapAll converts exchange rates of multiple days, such as returned by FetchAll, same as Map.
+func MapAll(rates map[time.Time]ExchangeRates) map[time.Time]map[string]float64 {
+	res := make(map[time.Time]map[string]float64, len(rates))
+	for t, dayData := range rates {
+		res[t] = dayData.Map()
+	}
+	return res
+}
+
+// Inverse returns new collection where each rate is expressed as amount of EUR per unit of currency
+// rounded to prec digits. Zero rates are converted to +Inf, use InverseE to detect them.
+func (e ExchangeRates) Inverse(prec int) ExchangeRates {
+	res, _ := e.InverseE(prec)
+	return res
+}
+
+// InverseE returns inverse rates same as Inverse along with error if any of the rates is zero.
+func (e ExchangeRates) InverseE(prec int) (res ExchangeRates, err error) {
+	res = make(ExchangeRates, len(e))
+	for idx, v := range e {
+		res[idx] = ExchangeRate{Currency: v.Currency}
+		r, ok := ratFromFloat(v.Rate)
+		if !ok || r.Sign() == 0 {
+			res[idx].Rate = math.Inf(1)
+			if err == nil {
+				err = errors.New(fmt.Sprintf("Exchange rate for %s is %v, inverse can't be computed", v.Currency, v.Rate))
+			}
+			continue
+		}
+		res[idx].Rate = ratToFixed(r.Inv(r), prec, HalfUp)
+	}
+	return
+}
+
 // newExchangeRate returns new populated exchangeRate instance.
 func newExchangeRate(r *RawExchangeRate) (rate ExchangeRateInterface, err error) {
 	var v float64
-	v, err = strconv.ParseFloat(r.Rate, 32)
+	// Rates are published with few significant digits, float64 holds them without loss
+	// so that their exact decimal value can be recovered during computation.
+	v, err = strconv.ParseFloat(r.Rate, 64)
 	if err != nil {
-		return rate, errors.New(fmt.Sprintf("Invalid input rate value for %s, %s", r.Currency, r.Rate))
+		return rate, &ParseError{Currency: r.Currency, Value: r.Rate, Err: err}
 	}
 	return &ExchangeRate{
 		Currency: r.Currency,
@@ -89,100 +163,906 @@ func newExchangeRate(r *RawExchangeRate) (rate ExchangeRateInterface, err error)
 
 // XRefInterface represents basic interface used for fetching and converting exchange rates.
 type XRefInterface interface {
-	fetchXML() error
+	XRefClient
+	fetchXML(context.Context) error
 	round(float64, ...int) float64
-	computeExchangeValue(float64, *ExchangeRate, *ExchangeRate) (float64, error)
+	computeExchangeValue(float64, *ExchangeRate, *ExchangeRate, ...int) (float64, error)
+}
+
+// XRefClient represents exported methods of the client. Unlike XRefInterface it can be implemented
+// outside of the package, e.g. by fakes used in tests of code depending on the client.
+type XRefClient interface {
 	Convert(float64, string, string, time.Time) (float64, error)
+	ConvertDetailed(float64, string, string, time.Time) (ConversionResult, error)
+	ConvertMany(string, []ConversionRequest, time.Time) ([]ConversionResult, error)
+	SmartConvert(float64, string, string, time.Time) (float64, error)
+	QuoteSource(float64, string, string, time.Time, int) (float64, float64, error)
+	ConvertWatchlist(float64, string, map[string]int, time.Time) (map[string]float64, error)
+	ConvertSplit(float64, string, map[string]float64, time.Time) (map[string]float64, error)
+	ConvertContext(context.Context, float64, string, string, time.Time) (float64, error)
+	ConvertPrec(float64, string, string, time.Time, int) (float64, error)
+	ConvertString(string, string, string, time.Time) (string, error)
+	ConvertFormatted(float64, string, string, time.Time) (string, error)
+	ConvertVia(float64, string, string, string, time.Time) (float64, error)
+	ConvertWithSpread(float64, string, string, time.Time, float64) (float64, error)
+	ConvertAt(float64, string, string, string) (float64, error)
 	Fetch(time.Time) (ExchangeRates, error)
+	FetchContext(context.Context, time.Time) (ExchangeRates, error)
+	FetchWithDate(time.Time) (ExchangeRates, time.Time, error)
+	FetchAt(string) (ExchangeRates, error)
+	FetchBase(time.Time, string) (ExchangeRates, error)
+	RateTable(time.Time) (ExchangeRates, error)
+	Rate(string, time.Time) (float64, error)
+	EURRate(string, time.Time) (float64, error)
+	ListCurrencies(time.Time) ([]string, error)
+	CrossRateMatrix(time.Time) (map[string]map[string]float64, error)
+	ConvertRange(float64, string, string, time.Time, time.Time) (map[time.Time]float64, error)
+	FetchHistorical(time.Time) (ExchangeRates, error)
+	FetchLatest() (ExchangeRates, time.Time, error)
 	FetchAll() (map[time.Time]ExchangeRates, error)
+	FetchAllContext(context.Context) (map[time.Time]ExchangeRates, error)
+	FetchAllAligned() (map[time.Time]map[string]float64, []string, error)
+	FetchRange(time.Time, time.Time) (map[time.Time]ExchangeRates, error)
+	FetchStream(func(time.Time, ExchangeRates) error) error
+	FetchStreamContext(context.Context, func(time.Time, ExchangeRates) error) error
+	CurrencyCoverage(string) (time.Time, time.Time, int, error)
+	DateRange() (time.Time, time.Time, error)
+	PublishedDates(time.Time, time.Time) ([]time.Time, error)
+	PrewarmRecent(int) error
+	PairChange(string, string, time.Time, time.Time) (float64, float64, float64, error)
+	PercentChange(string, time.Time, time.Time) (float64, error)
+	MovingAverage(string, time.Time, int) (float64, error)
+	RateSlices(string, time.Time, time.Time) ([]time.Time, []float64, error)
+	RateExtremes(string, time.Time, time.Time) (float64, float64, time.Time, time.Time, error)
+	CurrencyCatalog() ([]CurrencyMeta, error)
+	ResolveDate(time.Time) (time.Time, bool)
+	ConvertCSV(io.Reader, io.Writer) error
+	ExportCSV(io.Writer, time.Time, time.Time) error
+	FetchDay(time.Time) (DayRates, error)
+	FetchNearest(time.Time) (ExchangeRates, time.Time, error)
+	Ready() bool
+	Warm(context.Context) error
+	LastFetched() time.Time
+	DataAge() time.Duration
+	Healthcheck(context.Context) error
+	VerifyTriangulation(time.Time, float64) ([]TriangulationIssue, error)
+	LatestDate() (time.Time, error)
+	Refresh(context.Context) error
+	Subscribe(context.Context, time.Duration) (<-chan RateUpdate, error)
+	InvalidateCache() error
+	LoadFrom(io.Reader) error
+	Snapshot(io.Writer) error
+	Restore(io.Reader) error
+	SetFallback(io.Reader) error
+	Stale() bool
+	Close() error
 }
 
 // Client containing all data required for interaction with euroxref.
 type Client struct {
-	// HTTP client used for retrieving data.
+	// HTTP client used for retrieving data, New creates a dedicated one limited by DefaultTimeout.
 	HTTPClient *http.Client
+	// Url from which exchange rate data is retrieved, ECB 90 day feed by default.
+	SourceURL string
 	// Fetched currency exchange data.
 	XRefData *XRefRawResponse
 	// Amount of time in seconds after which exchange list will be refreshed. If set to 0 list of currencies are refreshed every time.
 	RefreshInterval int
+	// If set, dates without published rates (e.g. weekends) resolve to the closest earlier date with data.
+	FallbackToPrevious bool
+	// Location in which requested times are interpreted, if nil times are used in their own location.
+	Location *time.Location
+	// Number of times retrieval of the data is retried on network errors and 5xx responses.
+	MaxRetries int
+	// Delay before first retry, doubled with every subsequent one. DefaultRetryBackoff if 0.
+	RetryBackoff time.Duration
+	// If set, previously retrieved data is used when it can't be refreshed instead of returning an error.
+	ServeStale bool
+	// Directory in which retrieved data is persisted between process restarts, disabled if empty.
+	CacheDir string
+	// Period for which data persisted in CacheDir is used instead of downloading it, RefreshInterval if 0.
+	CacheTTL time.Duration
+	// Storage of retrieved data shared with other clients, consulted before downloading data
+	// and populated after it for CacheTTL (RefreshInterval if 0). Disabled if nil.
+	Cache Cache
+	// Called before every request for exchange rate data with url of the request.
+	// Callbacks may be called while data of the client is locked so they must not call methods of the client.
+	OnFetchStart func(url string)
+	// Called after every request for exchange rate data with its duration and error if it failed.
+	OnFetchDone func(url string, dur time.Duration, err error)
+	// Called whenever data is served without retrieving it again as it's still fresh.
+	OnCacheHit func()
+	// If set, rates which can't be parsed are omitted instead of failing retrieval of the whole day.
+	SkipMalformed bool
+	// Called for every rate omitted because of SkipMalformed. Same as other callbacks it may be called
+	// while data of the client is locked so it must not call methods of the client.
+	// Days are parsed concurrently (see WithParseWorkers), so it may be called from multiple goroutines at once.
+	OnMalformed func(err *ParseError)
+	// If set, retrieved data has to look like ECB feed: Envelope root element with at least one day,
+	// every day having a valid date and at least one rate. Otherwise retrieval fails with error describing
+	// the problem, e.g. when a mirror serves HTML page, instead of rates being reported as unavailable.
+	StrictXML bool
+	// If set, currency codes passed for conversion are validated against ISO 4217 before retrieving any data.
+	StrictCurrencies bool
+	// If set, negative amounts such as refunds can be converted, otherwise they're rejected with an error.
+	AllowNegativeAmounts bool
+	// If set, results of conversions other than ConvertPrec are rounded to standard decimal places
+	// of target currency (see CurrencyDecimals) instead of client precision, e.g. 0 for JPY.
+	UseCurrencyDecimals bool
+	// Maximum age of retrieved data accepted by Healthcheck, age isn't checked if 0.
+	MaxDataAge time.Duration
 	// Precision to be used for computational rounding of values.
 	prec int
+	// Guards lazy initialization of HTTPClient.
+	httpMu sync.Mutex
+	// Clients used for retrieving data from other feeds keyed by their url.
+	feeds map[string]*Client
+	// Guards feeds.
+	feedsMu sync.Mutex
+	// Download of the data in progress shared by concurrent callers.
+	flight *fetchCall
+	// Guards flight.
+	flightMu sync.Mutex
+	// Guards XRefData, lastFetched, cachedUntil, closed, parsed, fallback, rechecked, validators and validated.
+	mu sync.RWMutex
 	// Last time when data was fetched from remote server.
 	lastFetched time.Time
+	// Time until which data loaded from CacheDir is considered fresh.
+	cachedUntil time.Time
+	// Whether Close was called.
+	closed bool
+	// Parsed exchange rates keyed by date, reset whenever data is refreshed.
+	parsed map[string]ExchangeRates
+	// Guards parsed while it's populated under read lock.
+	parsedMu sync.Mutex
+	// Records of XRefData with published rates keyed by their date.
+	index map[string]*XRefRawData
+	// Data for which index was built.
+	indexed *XRefRawResponse
+	// Snapshot served when data can't be retrieved and there's no other data, nil if not set.
+	fallback *XRefRawResponse
+	// Data which was already refreshed because of a recent date missing from it, see recheckRecent.
+	rechecked *XRefRawResponse
+	// Validators of the last response downloaded from SourceURL used for conditional requests.
+	validators feedValidators
+	// Data to which validators belong, they're only used while it's held in XRefData.
+	validated *XRefRawResponse
+	// Set by InvalidateCache so that data isn't taken from Cache until it's downloaded again.
+	invalidated bool
+	// Guards index while it's built under read lock.
+	indexMu sync.Mutex
+	// Path to the gob file used for persisting parsed data, empty if disabled.
+	gobCachePath string
+	// Whether rates from full history feed take precedence over 90 day feed.
+	preferRevised bool
+	// Whether rates from daily feed are merged into the data.
+	mergeDaily bool
+	// Maximum number of days fallback search can go back, 0 if unlimited.
+	maxFallbackDays int
+	// Percentage change of rate between consecutive days reported to onJump.
+	jumpThreshold float64
+	// Callback notified about suspicious rate changes, nil if disabled.
+	onJump func(RateJump)
+	// Precision to which amounts are rounded before conversion, DefaultAmountPrecision if 0.
+	amountPrec int
+	// Whether parsed rates are rounded to client precision.
+	roundRates bool
+	// Increment to which final conversion results are rounded, 0 if disabled.
+	roundingIncrement float64
+	// Rounding mode used for all computations, HalfUp by default.
+	roundingMode RoundingMode
+	// Limiter of requests for exchange rate data, nil if unlimited.
+	limiter *rateLimiter
+	// Direction in which ConvertWithSpread moves the rate, SpreadBid by default.
+	spreadSide SpreadSide
+	// Number of goroutines parsing days of data, GOMAXPROCS if less than 1.
+	parseWorkers int
 }
 
 // New() returns new instance of XRefInterface.
-// precision paramenter defines float precision when calculating exchange rates.
+// precision paramenter defines float precision of conversion results, rates are kept at full
+// precision published by ECB and only the final value is rounded (see WithRoundedRates).
 // refresh interval defines how often (in seconds) xml data will be downloaded after last fetch
 // from the server, if set to 0, data will be fetched every time.
-func New(precision, refreshInterval uint) (client XRefInterface) {
-	return &Client{
-		HTTPClient:      http.DefaultClient,
+// opts allow for customizing optional behaviour of the client.
+func New(precision, refreshInterval uint, opts ...Option) (client XRefInterface) {
+	return NewClient(precision, refreshInterval, opts...)
+}
+
+// NewClient returns new instance of Client same as New, but as concrete type so that its fields
+// can be set without type assertion.
+func NewClient(precision, refreshInterval uint, opts ...Option) *Client {
+	c := &Client{
+		HTTPClient:      &http.Client{Timeout: DefaultTimeout},
+		SourceURL:       exchangeReferenceRatesUrl,
 		prec:            int(precision),
 		RefreshInterval: int(refreshInterval),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-// roundFloat rounds the float into nearest integer.
-func roundFloat(num float64) uint64 {
-	const roundBarrier = 0.5
-	return uint64(num + math.Copysign(roundBarrier, num))
+// NewValidated returns new instance of XRefInterface, same as New,
+// but rejects parameters outside of supported range with *ValidationError.
+func NewValidated(precision, refreshInterval uint, opts ...Option) (client XRefInterface, err error) {
+	if precision > MaxPrecision {
+		return nil, &ValidationError{
+			Param:  "precision",
+			Value:  precision,
+			Reason: fmt.Sprintf("must not be greater than %d", MaxPrecision),
+		}
+	}
+	if refreshInterval > MaxRefreshInterval {
+		return nil, &ValidationError{
+			Param:  "refreshInterval",
+			Value:  refreshInterval,
+			Reason: fmt.Sprintf("must not be greater than %d seconds", MaxRefreshInterval),
+		}
+	}
+	client = New(precision, refreshInterval, opts...)
+	if inc := client.(*Client).roundingIncrement; inc < 0 {
+		return nil, &ValidationError{
+			Param:  "roundingIncrement",
+			Value:  inc,
+			Reason: "must not be negative",
+		}
+	}
+	if source := client.(*Client).SourceURL; !isValidSourceURL(source) {
+		return nil, &ValidationError{
+			Param:  "SourceURL",
+			Value:  source,
+			Reason: "must be an absolute http or https url",
+		}
+	}
+	if days := client.(*Client).maxFallbackDays; days < 0 {
+		return nil, &ValidationError{
+			Param:  "maxFallbackDays",
+			Value:  days,
+			Reason: "must not be negative",
+		}
+	}
+	if mode := client.(*Client).roundingMode; !mode.valid() {
+		return nil, &ValidationError{
+			Param:  "roundingMode",
+			Value:  mode,
+			Reason: "unknown rounding mode",
+		}
+	}
+	return client, nil
 }
 
-// FloatToFixed rounds floating number based on precision of computation.
+// FloatToFixed rounds floating number half away from zero based on precision of computation.
+// Values too large to be represented with given precision (|num| * 10^prec >= 2^53) are returned unchanged.
 func FloatToFixed(num float64, prec int) float64 {
-	// Force precision to be at least one
-	if prec < 1 {
-		prec = 1
-	}
-	exp := math.Pow(10, float64(prec))
-	return float64(roundFloat(num*exp)) / exp
+	return FloatToFixedMode(num, prec, HalfUp)
 }
 
 // FetchXML retrieves xml containing currency Data and parses it into XRefRawResponse
-func (c *Client) fetchXML() (err error) {
+// Concurrent callers which need the data refreshed share a single download instead of downloading the data again.
+func (c *Client) fetchXML(ctx context.Context) (err error) {
+	if done, err := c.checkFresh(); done {
+		return err
+	}
+	return c.fetchShared(ctx)
+}
+
+// checkFresh reports whether data doesn't have to be retrieved, either because it's fresh
+// or because the client is closed in which case ErrClientClosed is returned.
+func (c *Client) checkFresh() (done bool, err error) {
+	c.mu.RLock()
+	fresh, closed := c.isFresh(), c.closed
+	c.mu.RUnlock()
+	if closed {
+		return true, ErrClientClosed
+	}
+	if fresh {
+		c.cacheHit()
+	}
+	return fresh, nil
+}
+
+// fetchData retrieves data same as fetchXML, if force is set data is downloaded
+// regardless of its freshness and without consulting any caches.
+func (c *Client) fetchData(ctx context.Context, force bool) (err error) {
+	if !force {
+		if done, err := c.checkFresh(); done {
+			return err
+		}
+	}
+	if done, err := c.seedData(force); done {
+		return err
+	}
+	// Data is downloaded without holding the lock so that it can still be read meanwhile,
+	// concurrent downloads are collapsed into one by fetchShared instead.
+	data, validators, err := c.download(ctx)
+	// Jumps are reported once the lock is released, deferred calls run in reverse order.
+	var jumps []RateJump
+	defer func() {
+		c.reportJumps(jumps)
+	}()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrClientClosed
+	}
+	// Server confirmed that data held by the client didn't change, so it's kept as if it was downloaded again.
+	notModified := errors.Is(err, errNotModified) && c.XRefData != nil
+	if notModified {
+		data, err = c.XRefData, nil
+	}
+	if data == nil || err != nil {
+		// Keep serving previously retrieved data if the server is unavailable.
+		if c.ServeStale && c.XRefData != nil && ctx.Err() == nil {
+			return nil
+		}
+		// Without any live data fall back to the snapshot, until data can be retrieved.
+		if c.fallback != nil && (c.XRefData == nil || c.XRefData == c.fallback) && ctx.Err() == nil {
+			if c.XRefData != c.fallback {
+				c.XRefData = c.fallback
+				c.parsed = nil
+			}
+			return nil
+		}
+		return
+	}
+	if !notModified {
+		jumps = c.detectJumps(c.XRefData, data)
+		c.XRefData = data
+		c.parsed = nil
+		c.validators = validators
+		c.validated = data
+	}
+	c.lastFetched = time.Now()
+	c.cachedUntil = time.Time{}
+	c.invalidated = false
+	// Persisting the caches is best effort, it shouldn't fail retrieval of the data.
+	if c.gobCachePath != "" {
+		c.writeGobCache()
+	}
+	if c.CacheDir != "" {
+		c.writeXMLCache()
+	}
+	c.storeSharedCache()
+	return nil
+}
+
+// seedData reports whether data doesn't have to be downloaded, loading it from caches on first use
+// unless force is set. ErrClientClosed is returned if the client is closed.
+func (c *Client) seedData(force bool) (done bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return true, ErrClientClosed
+	}
+	if force {
+		return
+	}
+	// Failing to load data from caches simply means it will be downloaded.
+	if c.XRefData == nil && c.gobCachePath != "" {
+		c.loadGobCache()
+	}
+	if c.XRefData == nil && c.CacheDir != "" {
+		c.loadXMLCache()
+	}
+	// Data might have been refreshed by another goroutine while waiting for the lock.
+	if c.isFresh() || (!c.invalidated && c.loadSharedCache()) {
+		c.cacheHit()
+		return true, nil
+	}
+	return
+}
+
+// download retrieves data from SourceURL, overlaid with revised rates if preferRevised is set
+// and merged with daily feed if mergeDaily is set. Data is returned only if all feeds were retrieved.
+// Unless data is merged from multiple feeds, it's requested conditionally using validators of data
+// held by the client, errNotModified is returned if it didn't change.
+// Validators of retrieved data are returned along with it.
+func (c *Client) download(ctx context.Context) (data *XRefRawResponse, validators feedValidators, err error) {
+	if c.SourceURL == "" {
+		return nil, validators, errors.New("Source url for exchange rate data is not set")
+	}
+	// Feed which SourceURL already points to isn't retrieved again.
+	revise := c.preferRevised && c.SourceURL != historicalReferenceRatesUrl
+	merge := c.mergeDaily && c.SourceURL != dailyReferenceRatesUrl
+	if revise || merge {
+		// Validators of a single feed don't account for the other ones, so merged data is always downloaded.
+		data, err = c.fetchFeedRetry(ctx, c.SourceURL, nil)
+	} else {
+		c.mu.RLock()
+		validators = c.conditionalValidators()
+		c.mu.RUnlock()
+		data, err = c.fetchFeedRetry(ctx, c.SourceURL, &validators)
+	}
+	if err == nil && revise {
+		var revised *XRefRawResponse
+		revised, err = c.fetchFeedRetry(ctx, historicalReferenceRatesUrl, nil)
+		if err != nil {
+			// Unrevised data would be served as fresh until RefreshInterval elapses.
+			return nil, validators, err
+		}
+		data = overlayFeed(data, revised)
+	}
+	if err == nil && merge {
+		var daily *XRefRawResponse
+		daily, err = c.fetchFeedRetry(ctx, dailyReferenceRatesUrl, nil)
+		if err != nil {
+			// Data without the present day would be served as fresh until RefreshInterval elapses.
+			return nil, validators, err
+		}
+		data = mergeDailyFeed(data, daily)
+	}
+	return
+}
+
+// cacheHit notifies OnCacheHit callback if it's set.
+func (c *Client) cacheHit() {
+	if c.OnCacheHit != nil {
+		c.OnCacheHit()
+	}
+}
+
+// isFresh checks whether data was fetched within RefreshInterval, c.mu has to be held by the caller.
+func (c *Client) isFresh() bool {
 	// If Refresh interval is greater than 0 and it's greater than time elapsed from last fetch
 	// don't download data again.
-	if (int(time.Now().Sub(c.lastFetched).Seconds()) < c.RefreshInterval) && (c.RefreshInterval > 0) {
+	// Data loaded from CacheDir stays fresh until its CacheTTL expires.
+	if time.Now().Before(c.cachedUntil) {
+		return true
+	}
+	return (int(time.Now().Sub(c.lastFetched).Seconds()) < c.RefreshInterval) && (c.RefreshInterval > 0)
+}
+
+// recheckRecent refreshes data once regardless of RefreshInterval if t is newer than the latest date held
+// but not in the future, i.e. rates for it may have been published since data was retrieved.
+// Data is rechecked only once until it's replaced, failing to refresh it leaves it as it was.
+func (c *Client) recheckRecent(ctx context.Context, t time.Time) {
+	c.mu.RLock()
+	miss := c.recentMiss(t)
+	c.mu.RUnlock()
+	if !miss {
 		return
 	}
-	resp, err := http.Get(exchangeReferenceRatesUrl)
+	c.mu.Lock()
+	// Another goroutine might have started rechecking the same data meanwhile.
+	if !c.recentMiss(t) {
+		c.mu.Unlock()
+		return
+	}
+	c.rechecked = c.XRefData
+	c.mu.Unlock()
+	c.fetchData(ctx, true)
+	c.mu.Lock()
+	c.rechecked = c.XRefData
+	c.mu.Unlock()
+}
+
+// recentMiss checks if held data, which wasn't rechecked yet, lacks rates for t while rates for it
+// could have been published since it was retrieved. c.mu has to be held by the caller.
+func (c *Client) recentMiss(t time.Time) bool {
+	// Data which is downloaded on every use or never expires can't get any more recent.
+	if c.XRefData == nil || c.rechecked == c.XRefData || !c.isFresh() || c.cachedUntil.Equal(neverExpires) {
+		return false
+	}
+	key := c.dateKey(t)
+	if key > c.dateKey(time.Now()) {
+		return false
+	}
+	latest := c.latestRecord()
+	return latest != nil && key > latest.RateTime
+}
+
+// Warm downloads exchange rate data right away, so that a service can refuse to start
+// if rates can't be loaded instead of failing on first use. RefreshInterval starts from the time of warm-up.
+// Error is returned as well if retrieved data holds no rates, Ready reports true after Warm succeeds.
+func (c *Client) Warm(ctx context.Context) error {
+	if err := c.Refresh(ctx); err != nil {
+		return err
+	}
+	if !c.Ready() {
+		return errors.New("No exchange rate data available")
+	}
+	return nil
+}
+
+// Ready reports whether client holds usable exchange rate data, i.e. at least one successful fetch
+// populated it. Unlike other methods it never triggers retrieval of the data.
+func (c *Client) Ready() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.XRefData == nil {
+		return false
+	}
+	for _, dayD := range c.XRefData.Data {
+		if len(dayD.Rates) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// LastFetched returns time when data was last retrieved from remote server (or cache), zero if it never was.
+// It never triggers retrieval of the data.
+func (c *Client) LastFetched() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lastFetched
+}
+
+// DataAge returns time elapsed since data was last retrieved, 0 if it never was.
+// It never triggers retrieval of the data.
+func (c *Client) DataAge() time.Duration {
+	lastFetched := c.LastFetched()
+	if lastFetched.IsZero() {
+		return 0
+	}
+	return time.Since(lastFetched)
+}
+
+// LatestDate returns date of the most recent exchange rates published by ECB.
+// Compared with DataAge it tells whether ECB, rather than the client, didn't refresh the data recently.
+func (c *Client) LatestDate() (date time.Time, err error) {
+	err = c.fetchXML(context.Background())
 	if err != nil {
 		return
 	}
-	defer resp.Body.Close()
-	data := &XRefRawResponse{}
-	err = xml.NewDecoder(resp.Body).Decode(data)
-	c.XRefData = data
-	c.lastFetched = time.Now()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	record := c.latestRecord()
+	if record == nil {
+		return date, errors.New("No exchange rate data available")
+	}
+	return time.Parse(XRefDateLayout, record.RateTime)
+}
+
+// latestRecord returns record of the most recent day with any rates, nil if there's none.
+// c.mu has to be held by the caller.
+func (c *Client) latestRecord() (record *XRefRawData) {
+	for idx, dayD := range c.XRefData.Data {
+		// Dates are in XRefDateLayout so lexical order matches chronological one.
+		if len(dayD.Rates) > 0 && (record == nil || dayD.RateTime > record.RateTime) {
+			record = &c.XRefData.Data[idx]
+		}
+	}
+	return
+}
+
+// fetchFeed downloads and decodes exchange rate data from given url, conditionally if validators are set.
+// Returned data is nil only if request itself failed.
+// Request is aborted when ctx is done, in which case ctx.Err() is returned.
+func (c *Client) fetchFeed(ctx context.Context, url string, validators *feedValidators) (data *XRefRawResponse, err error) {
+	err = c.streamFeed(ctx, url, validators, func(dec *xml.Decoder) error {
+		data = &XRefRawResponse{}
+		return dec.Decode(data)
+	})
+	// Data decoded before the error, e.g. from a truncated response, must not replace data held by the client.
+	if err != nil {
+		return nil, err
+	}
+	if c.StrictXML {
+		if err = checkFeed(url, data); err != nil {
+			return nil, err
+		}
+	}
+	dedupeDays(data)
+	return
+}
+
+// checkFeed verifies that data retrieved from url has structure of ECB feed, as decoder ignores
+// unknown elements and data of any other XML document simply decodes as empty.
+func checkFeed(url string, data *XRefRawResponse) error {
+	if data.XMLName.Local != "Envelope" {
+		return errors.New(fmt.Sprintf("Unexpected root element <%s> in exchange rate data from %s, expected <Envelope>", data.XMLName.Local, url))
+	}
+	if len(data.Data) == 0 {
+		return errors.New(fmt.Sprintf("No days found in exchange rate data from %s", url))
+	}
+	for _, dayD := range data.Data {
+		if _, err := time.Parse(XRefDateLayout, dayD.RateTime); err != nil {
+			return errors.New(fmt.Sprintf("Invalid date %q in exchange rate data from %s", dayD.RateTime, url))
+		}
+		if len(dayD.Rates) == 0 {
+			return errors.New(fmt.Sprintf("No rates found for %s in exchange rate data from %s", dayD.RateTime, url))
+		}
+	}
+	return nil
+}
+
+// dedupeDays removes all but the last occurrence of every date listed in data more than once.
+func dedupeDays(data *XRefRawResponse) {
+	last := make(map[string]int, len(data.Data))
+	for idx, dayD := range data.Data {
+		last[dayD.RateTime] = idx
+	}
+	if len(last) == len(data.Data) {
+		return
+	}
+	days := make([]XRefRawData, 0, len(last))
+	for idx, dayD := range data.Data {
+		if last[dayD.RateTime] == idx {
+			days = append(days, dayD)
+		}
+	}
+	data.Data = days
+}
+
+// streamFeed requests data from given url and passes decoder reading the response to decode.
+// If decode returns ErrStopStream remainder of the response is discarded and nil is returned.
+// If validators are given the request is conditional, errNotModified is returned if server reports
+// that data didn't change, otherwise validators are replaced with the ones of the response.
+// Request is aborted when ctx is done, in which case ctx.Err() is returned.
+func (c *Client) streamFeed(ctx context.Context, url string, validators *feedValidators, decode func(*xml.Decoder) error) (err error) {
+	// Waiting for the limiter doesn't count towards duration of the request.
+	if c.limiter != nil {
+		if err = c.limiter.wait(ctx); err != nil {
+			return
+		}
+	}
+	if c.OnFetchStart != nil {
+		c.OnFetchStart(url)
+	}
+	if c.OnFetchDone != nil {
+		start := time.Now()
+		defer func() {
+			doneErr := err
+			// Unchanged data is a successful outcome of the request.
+			if errors.Is(err, errNotModified) {
+				doneErr = nil
+			}
+			c.OnFetchDone(url, time.Since(start), doneErr)
+		}()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return
+	}
+	if validators != nil {
+		validators.setConditional(req)
+	}
+	// Compression is requested explicitly so that transport leaves decoding to decodedBody,
+	// which copes with servers declaring gzip encoding for plain responses.
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return
+	}
+	stopped := false
+	// Body is drained even if it couldn't be decoded so that the connection can be reused,
+	// unless decoding was stopped early in which case reading the rest of it would defeat the purpose.
+	defer func() {
+		if !stopped {
+			io.Copy(ioutil.Discard, resp.Body)
+		}
+		resp.Body.Close()
+	}()
+	if resp.StatusCode == http.StatusNotModified && validators != nil && validators.isSet() {
+		return errNotModified
+	}
+	if resp.StatusCode != http.StatusOK {
+		snippet, _ := ioutil.ReadAll(io.LimitReader(resp.Body, statusErrorBodyLimit))
+		return &StatusError{
+			URL:        url,
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       strings.TrimSpace(string(snippet)),
+		}
+	}
+	if validators != nil {
+		*validators = feedValidators{
+			etag:         resp.Header.Get("ETag"),
+			lastModified: resp.Header.Get("Last-Modified"),
+		}
+	}
+	body, err := decodedBody(resp, url)
+	if err != nil {
+		return
+	}
+	err = decode(xml.NewDecoder(body))
+	if errors.Is(err, ErrStopStream) {
+		stopped = true
+		err = nil
+	}
 	return
 }
 
+// Close releases idle connections held by the client, after which the client can't retrieve data anymore
+// and returns ErrClientClosed instead.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	c.feedsMu.Lock()
+	for _, fc := range c.feeds {
+		fc.Close()
+	}
+	c.feedsMu.Unlock()
+	c.httpClient().CloseIdleConnections()
+	return nil
+}
+
+// httpClient returns HTTP client used for retrieving data, defaulting to a dedicated one limited
+// by DefaultTimeout if Client was constructed without one, so that stalled server can't block retrieval forever.
+func (c *Client) httpClient() *http.Client {
+	c.httpMu.Lock()
+	defer c.httpMu.Unlock()
+	if c.HTTPClient == nil {
+		c.HTTPClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	return c.HTTPClient
+}
+
 // round rounds the value based on precision set during client initialization
 // or precision passed as optional arg.
 func (c *Client) round(num float64, params ...int) float64 {
 	if len(params) > 0 {
 		prec := params[0]
-		return FloatToFixed(num, prec)
+		return FloatToFixedMode(num, prec, c.roundingMode)
 	} else {
-		return FloatToFixed(num, c.prec)
+		return FloatToFixedMode(num, c.prec, c.roundingMode)
+
+	}
+}
+
+// roundResult rounds final conversion result based on client precision or precision passed as optional arg
+// and rounding increment if one is set.
+func (c *Client) roundResult(num float64, params ...int) float64 {
+	exact, ok := ratFromFloat(num)
+	if !ok {
+		return c.round(num, c.resultPrecision(params...))
+	}
+	res, _ := c.roundResultRat(exact, params...).Float64()
+	return res
+}
 
+// roundResultRat rounds exact conversion result same as roundResult.
+func (c *Client) roundResultRat(exact *big.Rat, params ...int) *big.Rat {
+	if inc, ok := ratFromFloat(c.roundingIncrement); ok && inc.Sign() > 0 {
+		steps := roundRat(new(big.Rat).Quo(exact, inc), 0, c.roundingMode)
+		exact = new(big.Rat).Mul(steps, inc)
+	}
+	return roundRat(exact, c.resultPrecision(params...), c.roundingMode)
+}
+
+// resultPrecision returns precision passed as optional arg or client precision (at least one)
+// to which conversion results are rounded.
+func (c *Client) resultPrecision(params ...int) int {
+	if len(params) > 0 {
+		return params[0]
+	}
+	if c.prec < 1 {
+		return 1
 	}
+	return c.prec
 }
 
 // computeExchangeValue returns computed value of exchange rate between 2 currencies
-// and passed value.
-func (c *Client) computeExchangeValue(amount float64, in, to *ExchangeRate) (result float64, err error) {
-	if amount < 0 {
+// and passed value. Result is rounded to client precision or precision passed as optional arg.
+func (c *Client) computeExchangeValue(amount float64, in, to *ExchangeRate, params ...int) (result float64, err error) {
+	if amount < 0 && !c.AllowNegativeAmounts {
 		return result, errors.New("Amount of conversion currency can't be negative")
 	}
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return result, errors.New("Amount of conversion currency has to be a finite number")
+	}
 	// If currencies are the same there's no need to perform any computation.
 	if in.Currency == to.Currency {
-		result = c.round(amount)
+		result = c.roundResult(amount, params...)
 		return
 	}
 	// Computation of exchange rate between currency A and B is performed by eliminating common denominator of EUR value as all exchange rates are relative to it. ((rateB/rateEUR)/(rateA/rateEUR)) == ((rateB/rateEUR) * (rateEUR/rateA)) == (rateB/rateA)
-	return c.round(c.round(amount, 2) * c.round(to.Rate/in.Rate)), nil
+	// Exact rate is used so that the result is rounded only once.
+	rate, err := crossRateRat(in, to)
+	if err != nil {
+		return
+	}
+	x, ok := ratFromFloat(amount)
+	if !ok {
+		return result, errors.New("Amount of conversion currency has to be a finite number")
+	}
+	result, _ = c.applyRateRat(x, rate, params...).Float64()
+	return
+}
+
+// pairRate returns rate of target currency per unit of source currency rounded to client precision.
+// Division is performed using decimal arithmetic so the rate isn't affected by binary floating point error.
+func (c *Client) pairRate(in, to *ExchangeRate) (rate float64, err error) {
+	exact, err := crossRateRat(in, to)
+	if err != nil {
+		return
+	}
+	return ratToFixed(exact, c.prec, c.roundingMode), nil
+}
+
+// amountPrecision returns precision to which amounts are rounded before conversion.
+func (c *Client) amountPrecision() int {
+	if c.amountPrec < 1 {
+		return DefaultAmountPrecision
+	}
+	return c.amountPrec
+}
+
+// applyRate returns result of converting amount using given exchange rate rounded same as in computeExchangeValue.
+// Multiplication is performed using decimal arithmetic, result is converted to float only once rounded.
+func (c *Client) applyRate(amount, rate float64, params ...int) float64 {
+	x, okX := ratFromFloat(amount)
+	y, okY := ratFromFloat(rate)
+	if !okX || !okY {
+		return c.roundResult(c.round(amount, c.amountPrecision())*rate, params...)
+	}
+	res, _ := c.applyRateRat(x, y, params...).Float64()
+	return res
+}
+
+// applyRateRat returns result of converting amount using given exchange rate, rounded same as applyRate.
+// Product of the amount and the rate is rounded only once.
+func (c *Client) applyRateRat(amount, rate *big.Rat, params ...int) *big.Rat {
+	x := roundRat(amount, c.amountPrecision(), c.roundingMode)
+	return c.roundResultRat(x.Mul(x, rate), params...)
+}
+
+// ConvertString converts amount given as decimal string same as Convert, returning result formatted
+// with precision it's rounded to. Amount is never represented as float so no precision is lost on the way.
+func (c *Client) ConvertString(amount, source, target string, t time.Time) (result string, err error) {
+	x, err := parseDecimal(amount)
+	if err != nil {
+		return
+	}
+	res, prec, err := c.convertRat(x, source, target, t)
+	if err != nil {
+		return
+	}
+	return res.FloatString(prec), nil
+}
+
+// ConvertFormatted converts amount same as Convert, returning result formatted with precision it's rounded to
+// as decimal string. The result stays exact even for precisions and magnitudes which float64 can't represent,
+// where Convert has to return the value unrounded, and matches Convert wherever float64 suffices.
+func (c *Client) ConvertFormatted(amount float64, source, target string, t time.Time) (result string, err error) {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return result, errors.New("Amount of conversion currency has to be a finite number")
+	}
+	x, _ := ratFromFloat(amount)
+	res, prec, err := c.convertRat(x, source, target, t)
+	if err != nil {
+		return
+	}
+	return res.FloatString(prec), nil
+}
+
+// convertRat converts exact amount same as Convert, without converting it to float.
+// Precision to which the result is rounded is returned along with it.
+func (c *Client) convertRat(x *big.Rat, source, target string, t time.Time) (res *big.Rat, prec int, err error) {
+	if x.Sign() < 0 && !c.AllowNegativeAmounts {
+		return nil, prec, errors.New("Amount of conversion currency can't be negative")
+	}
+	var dayData ExchangeRates
+	var in, to *ExchangeRate
+	codes, err := c.checkCurrencies(source, target)
+	if err != nil {
+		return
+	}
+	dayData, err = c.Fetch(t)
+	if err != nil {
+		return
+	}
+	in, to, err = lookupPair(dayData, codes[0], codes[1], t)
+	if err != nil {
+		return
+	}
+	params := c.resultParams(codes[1])
+	prec = c.resultPrecision(params...)
+	if in.Currency == to.Currency {
+		return c.roundResultRat(x, params...), prec, nil
+	}
+	rate, err := crossRateRat(in, to)
+	if err != nil {
+		return
+	}
+	return c.applyRateRat(x, rate, params...), prec, nil
 }
 
 // Convert is main method for computing exchange rates between currencies.
@@ -190,12 +1070,170 @@ func (c *Client) computeExchangeValue(amount float64, in, to *ExchangeRate) (res
 // source and target define currencies to compute exchange rates for.
 // t defines time for which exchange rates will be fetched.
 func (c *Client) Convert(amount float64, source, target string, t time.Time) (result float64, err error) {
+	return c.ConvertContext(context.Background(), amount, source, target, t)
+}
+
+// ConvertPrec converts amount same as Convert, rounding the result to prec digits instead of client precision,
+// e.g. 0 for currencies without minor units. Exchange rate between currencies is exact, so the result is rounded only once.
+func (c *Client) ConvertPrec(amount float64, source, target string, t time.Time, prec int) (result float64, err error) {
+	if prec < 0 || prec > MaxPrecision {
+		return result, &ValidationError{
+			Param:  "prec",
+			Value:  prec,
+			Reason: fmt.Sprintf("must be between 0 and %d", MaxPrecision),
+		}
+	}
 	var dayData ExchangeRates
 	var in, to *ExchangeRate
+	codes, err := c.checkCurrencies(source, target)
+	if err != nil {
+		return
+	}
 	dayData, err = c.Fetch(t)
 	if err != nil {
 		return
 	}
+	in, to, err = lookupPair(dayData, codes[0], codes[1], t)
+	if err != nil {
+		return
+	}
+	return c.computeExchangeValue(amount, in, to, prec)
+}
+
+// ConvertContext converts amount same as Convert, aborting retrieval of the data when ctx is done.
+func (c *Client) ConvertContext(ctx context.Context, amount float64, source, target string, t time.Time) (result float64, err error) {
+	var dayData ExchangeRates
+	codes, err := c.checkCurrencies(source, target)
+	if err != nil {
+		return
+	}
+	dayData, err = c.FetchContext(ctx, t)
+	if err != nil {
+		return
+	}
+	return c.convertDay(dayData, amount, codes[0], codes[1], t)
+}
+
+// convertDay converts amount same as Convert using exchange rates already fetched for t,
+// source and target have to be checked by checkCurrencies beforehand.
+func (c *Client) convertDay(dayData ExchangeRates, amount float64, source, target string, t time.Time) (result float64, err error) {
+	in, to, err := lookupPair(dayData, source, target, t)
+	if err != nil {
+		return
+	}
+	return c.computeExchangeValue(amount, in, to, c.resultParams(target)...)
+}
+
+// ConversionResult represents details of a single conversion.
+type ConversionResult struct {
+	// Nominal amount of source currency.
+	Amount float64
+	// Source currency.
+	Source string
+	// Target currency.
+	Target string
+	// Date of exchange rates used for conversion.
+	Date time.Time
+	// Effective exchange rate between source and target currency, rounded to client precision.
+	Rate float64
+	// Exact value of conversion without any rounding applied.
+	Exact float64
+	// Converted amount, same as returned by Convert.
+	Result float64
+	// Whether rates come from the snapshot set by SetFallback as live data couldn't be retrieved.
+	Stale bool
+	// Reason why conversion failed, only set for items of ConvertMany.
+	Err error
+}
+
+// ConversionRequest represents a single item of a batch conversion.
+type ConversionRequest struct {
+	// Nominal amount of source currency.
+	Amount float64
+	// Target currency.
+	Target string
+}
+
+// ConvertMany converts amounts of source currency into target currencies of items,
+// all of them using exchange rates of the same date which are retrieved once.
+// Results are returned in order of items, with Err set for items which couldn't be converted,
+// while err is only returned if none of them could, e.g. when rates aren't available for the date.
+func (c *Client) ConvertMany(source string, items []ConversionRequest, t time.Time) (results []ConversionResult, err error) {
+	codes, err := c.checkCurrencies(source)
+	if err != nil {
+		return
+	}
+	source = codes[0]
+	dayData, date, err := c.FetchWithDate(t)
+	if err != nil {
+		return
+	}
+	if _, _, err = lookupPair(dayData, source, source, t); err != nil {
+		return
+	}
+	results = make([]ConversionResult, len(items))
+	for idx, item := range items {
+		target := item.Target
+		codes, itemErr := c.checkCurrencies(target)
+		if itemErr == nil {
+			target = codes[0]
+			results[idx], itemErr = c.detailedResult(dayData, item.Amount, source, target, t, date)
+		}
+		if itemErr != nil {
+			results[idx] = ConversionResult{Amount: item.Amount, Source: source, Target: target, Date: date, Err: itemErr}
+		}
+	}
+	return
+}
+
+// ConvertDetailed converts amount same as Convert but returns details of the conversion
+// including the effective rate and unrounded result. Date of the result is the date of exchange rates
+// actually used, which with FallbackToPrevious can be earlier than t.
+func (c *Client) ConvertDetailed(amount float64, source, target string, t time.Time) (result ConversionResult, err error) {
+	var dayData ExchangeRates
+	var date time.Time
+	codes, err := c.checkCurrencies(source, target)
+	if err != nil {
+		return
+	}
+	source, target = codes[0], codes[1]
+	dayData, date, err = c.FetchWithDate(t)
+	if err != nil {
+		return
+	}
+	return c.detailedResult(dayData, amount, source, target, t, date)
+}
+
+// detailedResult converts amount using exchange rates of dayData, date is the date of the rates.
+func (c *Client) detailedResult(dayData ExchangeRates, amount float64, source, target string, t, date time.Time) (result ConversionResult, err error) {
+	in, to, err := lookupPair(dayData, source, target, t)
+	if err != nil {
+		return
+	}
+	exact, err := crossRateRat(in, to)
+	if err != nil {
+		return
+	}
+	result = ConversionResult{
+		Amount: amount,
+		Source: source,
+		Target: target,
+		Date:   date,
+		Rate:   ratToFixed(exact, c.prec, c.roundingMode),
+	}
+	if x, ok := ratFromFloat(amount); ok {
+		result.Exact, _ = x.Mul(x, exact).Float64()
+	}
+	result.Result, err = c.computeExchangeValue(amount, in, to, c.resultParams(target)...)
+	result.Stale = c.Stale()
+	return
+}
+
+// lookupPair finds exchange rates for source and target currencies in dayData, regardless of their case.
+// t is only used for reporting errors.
+func lookupPair(dayData ExchangeRates, source, target string, t time.Time) (in, to *ExchangeRate, err error) {
+	// Currency codes in the data are upper case.
+	source, target = strings.ToUpper(source), strings.ToUpper(target)
 	for idx, rec := range dayData {
 		if source == rec.Currency {
 			in = &dayData[idx]
@@ -224,38 +1262,260 @@ func (c *Client) Convert(amount float64, source, target string, t time.Time) (re
 		for _, rec := range dayData {
 			availableCurrencies = append(availableCurrencies, rec.Currency)
 		}
-		return result, errors.New(fmt.Sprintf("Invalid currencies selected: %s, %s. List of available currency rates: %s for %s", source, target, strings.Join(availableCurrencies, ", "), t.Format(XRefDateLayout)))
+		return in, to, &ErrCurrencyNotFound{
+			Currencies: []string{source, target},
+			Available:  availableCurrencies,
+			Date:       t,
+		}
 	}
-	return c.computeExchangeValue(amount, in, to)
+	return
 }
 
 // Fetch retrieves collection of exchangeRate values for given month.
+// If rates for t are missing from data which is still fresh, but t is newer than the latest date held
+// and not in the future, data is refreshed once regardless of RefreshInterval as rates may have been published since.
 func (c *Client) Fetch(t time.Time) (rates ExchangeRates, err error) {
-	timeKey := t.Format(XRefDateLayout)
-	var dayData []RawExchangeRate
-	err = c.fetchXML()
+	return c.FetchContext(context.Background(), t)
+}
+
+// FetchContext retrieves exchange rates same as Fetch, aborting retrieval of the data when ctx is done.
+func (c *Client) FetchContext(ctx context.Context, t time.Time) (rates ExchangeRates, err error) {
+	rates, _, err = c.fetchWithDate(ctx, t)
+	return
+}
+
+// FetchWithDate retrieves exchange rates same as Fetch along with the date of the record they come from,
+// which differs from the requested day only if FallbackToPrevious is set.
+func (c *Client) FetchWithDate(t time.Time) (rates ExchangeRates, date time.Time, err error) {
+	return c.fetchWithDate(context.Background(), t)
+}
+
+func (c *Client) fetchWithDate(ctx context.Context, t time.Time) (rates ExchangeRates, date time.Time, err error) {
+	err = c.fetchXML(ctx)
+	if err != nil {
+		return
+	}
+	c.recheckRecent(ctx, t)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	key, _, err := c.findDay(t, c.FallbackToPrevious)
+	if err != nil {
+		return
+	}
+	rates, err = c.parseDay(key)
+	if err != nil {
+		return
+	}
+	date, err = time.Parse(XRefDateLayout, key)
+	return
+}
+
+// Rate returns EUR relative exchange rate of currency for given day, 1 for EUR itself.
+// *ErrCurrencyNotFound is returned if there's no rate published for the currency.
+func (c *Client) Rate(currency string, t time.Time) (rate float64, err error) {
+	dayData, err := c.Fetch(t)
+	if err != nil {
+		return
+	}
+	in, _, err := lookupPair(dayData, currency, currency, t)
+	if err != nil {
+		return
+	}
+	return in.Rate, nil
+}
+
+// EURRate returns EUR relative exchange rate of currency for given day same as Rate,
+// handling EUR the same way as Convert does.
+func (c *Client) EURRate(currency string, t time.Time) (rate float64, err error) {
+	return c.Rate(currency, t)
+}
+
+// ListCurrencies returns alphabetically sorted codes of currencies available for given day,
+// always including EUR. If there's no data for the day same error as in Fetch is returned.
+func (c *Client) ListCurrencies(t time.Time) (currencies []string, err error) {
+	rates, err := c.Fetch(t)
 	if err != nil {
 		return
 	}
+	currencies = []string{EUCurr}
+	for _, rec := range rates {
+		if rec.Currency != EUCurr {
+			currencies = append(currencies, rec.Currency)
+		}
+	}
+	sort.Strings(currencies)
+	return
+}
+
+// DayRates represents exchange rates for a single day along with metadata about their origin.
+type DayRates struct {
+	// Date of the published rates.
+	Date time.Time
+	// Exchange rates including EUR.
+	Rates ExchangeRates
+	// Time when the data was retrieved from remote server.
+	FetchedAt time.Time
+	// Whether rates come from an earlier date than requested due to FallbackToPrevious.
+	Fallback bool
+}
+
+// FetchDay retrieves exchange rates for given day, including EUR, along with their metadata.
+func (c *Client) FetchDay(t time.Time) (day DayRates, err error) {
+	err = c.fetchXML(context.Background())
+	if err != nil {
+		return
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	key, fallback, err := c.findDay(t, c.FallbackToPrevious)
+	if err != nil {
+		return
+	}
+	rates, err := c.parseDay(key)
+	if err != nil {
+		return
+	}
+	date, _ := time.Parse(XRefDateLayout, key)
+	return DayRates{
+		Date:      date,
+		Rates:     append(ExchangeRates{{Currency: EUCurr, Rate: EURate}}, rates...),
+		FetchedAt: c.lastFetched,
+		Fallback:  fallback,
+	}, nil
+}
+
+// findDay returns key of the day record holding exchange rates for t. If there's no data for t and
+// fallback is enabled the closest earlier day with data is used, within maxFallbackDays if set.
+// c.mu has to be held by the caller.
+func (c *Client) findDay(t time.Time, fallback bool) (key string, fellBack bool, err error) {
+	date, _ := c.ResolveDate(t)
+	requested := date.Format(XRefDateLayout)
+	earliest := ""
+	if c.maxFallbackDays > 0 {
+		earliest = date.AddDate(0, 0, -c.maxFallbackDays).Format(XRefDateLayout)
+	}
+	if c.dayRecord(requested) != nil {
+		return requested, false, nil
+	}
+	if !fallback {
+		return key, false, &ErrDateNotFound{Date: date}
+	}
 	for _, dayD := range c.XRefData.Data {
-		if dayD.RateTime == timeKey {
-			dayData = dayD.Rates
-			break
+		// Dates are in XRefDateLayout so lexical order matches chronological one.
+		if len(dayD.Rates) > 0 && dayD.RateTime < requested && dayD.RateTime >= earliest && dayD.RateTime > key {
+			key = dayD.RateTime
 		}
 	}
-	if len(dayData) == 0 {
-		return rates, errors.New(fmt.Sprintf("Currency data for %s doesn't exist. Records are only available for past 90 days, excluding present day.", timeKey))
+	if key == "" {
+		return key, false, &ErrDateNotFound{Date: date}
+	}
+	return key, true, nil
+}
+
+// FetchNearest retrieves exchange rates for given day or, if there's no data for it,
+// the closest earlier day with data. Date of returned rates is returned alongside them.
+// Search is limited by WithMaxFallbackDays, *ErrDateNotFound is returned if no rates were found.
+func (c *Client) FetchNearest(t time.Time) (rates ExchangeRates, date time.Time, err error) {
+	err = c.fetchXML(context.Background())
+	if err != nil {
+		return
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	key, _, err := c.findDay(t, true)
+	if err != nil {
+		return
+	}
+	rates, err = c.parseDay(key)
+	if err != nil {
+		return
+	}
+	date, err = time.Parse(XRefDateLayout, key)
+	return
+}
+
+// parseDay returns parsed exchange rates for the day identified by timeKey.
+// Parsed results are kept in per-date cache until the data is refreshed.
+// c.mu has to be held by the caller.
+func (c *Client) parseDay(timeKey string) (rates ExchangeRates, err error) {
+	if record := c.dayRecord(timeKey); record != nil {
+		return c.parseRecord(record)
+	}
+	date, _ := time.Parse(XRefDateLayout, timeKey)
+	return rates, &ErrDateNotFound{Date: date}
+}
+
+// dayRecord returns record holding exchange rates published on the day identified by timeKey, nil if there's none.
+// Records are looked up in index keyed by date, rebuilt whenever XRefData is replaced.
+// c.mu has to be held by the caller.
+func (c *Client) dayRecord(timeKey string) *XRefRawData {
+	c.indexMu.Lock()
+	defer c.indexMu.Unlock()
+	if c.index == nil || c.indexed != c.XRefData {
+		c.index = make(map[string]*XRefRawData)
+		c.indexed = c.XRefData
+		if c.XRefData != nil {
+			for idx, dayD := range c.XRefData.Data {
+				if _, ok := c.index[dayD.RateTime]; !ok && len(dayD.Rates) > 0 {
+					c.index[dayD.RateTime] = &c.XRefData.Data[idx]
+				}
+			}
+		}
+	}
+	return c.index[timeKey]
+}
+
+// parseRecord returns parsed exchange rates of the day record, using the per-date cache same as parseDay.
+// c.mu has to be held by the caller.
+func (c *Client) parseRecord(record *XRefRawData) (rates ExchangeRates, err error) {
+	timeKey := record.RateTime
+	c.parsedMu.Lock()
+	cached, ok := c.parsed[timeKey]
+	c.parsedMu.Unlock()
+	if ok {
+		return append(ExchangeRates{}, cached...), nil
+	}
+	// Rates are parsed without holding parsedMu so that multiple days can be parsed at once.
+	rates, err = c.parseRates(record.RateTime, record.Rates)
+	if err != nil {
+		return
+	}
+	c.parsedMu.Lock()
+	defer c.parsedMu.Unlock()
+	if c.parsed == nil {
+		c.parsed = make(map[string]ExchangeRates)
 	}
+	c.parsed[timeKey] = append(ExchangeRates{}, rates...)
+	return
+}
+
+// parseRates converts raw exchange rates of a single day published on rateTime, rounding them
+// to precision of the client only if WithRoundedRates is set. Malformed rates fail the whole day
+// with *ParseError, unless SkipMalformed is set in which case they're reported to OnMalformed and omitted.
+func (c *Client) parseRates(rateTime string, dayData []RawExchangeRate) (rates ExchangeRates, err error) {
 	rates = ExchangeRates{}
 	var temp interface{}
 	for _, rec := range dayData {
 		temp, err = newExchangeRate(&rec)
+		var pErr *ParseError
+		if errors.As(err, &pErr) {
+			pErr.RateTime = rateTime
+			if c.SkipMalformed {
+				if c.OnMalformed != nil {
+					c.OnMalformed(pErr)
+				}
+				err = nil
+				continue
+			}
+		}
 		if err != nil {
 			return rates, err
 		}
 		// We can skip checking if value was casted succesfully here
 		val, _ := temp.(*ExchangeRate)
-		val.Round(c.prec)
+		if c.roundRates {
+			val.Rate = c.round(val.Rate)
+		}
 		rates = append(rates, *val)
 	}
 	return
@@ -263,24 +1523,51 @@ func (c *Client) Fetch(t time.Time) (rates ExchangeRates, err error) {
 
 // FetchAll retrieves all available exchangeRate records.
 func (c *Client) FetchAll() (rates map[time.Time]ExchangeRates, err error) {
-	err = c.fetchXML()
+	return c.FetchAllContext(context.Background())
+}
+
+// FetchAllContext retrieves all records same as FetchAll, aborting retrieval of the data when ctx is done.
+func (c *Client) FetchAllContext(ctx context.Context) (rates map[time.Time]ExchangeRates, err error) {
+	return c.fetchRange(ctx, time.Time{}, time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC))
+}
+
+// FetchRange retrieves exchange rates published between from and to (inclusive) keyed by their date.
+// Days without published rates are absent from the result.
+func (c *Client) FetchRange(from, to time.Time) (rates map[time.Time]ExchangeRates, err error) {
+	return c.fetchRange(context.Background(), from, to)
+}
+
+func (c *Client) fetchRange(ctx context.Context, from, to time.Time) (rates map[time.Time]ExchangeRates, err error) {
+	fromKey, toKey := c.dateKey(from), c.dateKey(to)
+	if fromKey > toKey {
+		return rates, errors.New(fmt.Sprintf("Invalid date range: %s is after %s", fromKey, toKey))
+	}
+	err = c.fetchXML(ctx)
 	if err != nil {
 		return
 	}
-	rates = make(map[time.Time]ExchangeRates)
-	var t time.Time
-	var d ExchangeRates
-	for _, dayD := range c.XRefData.Data {
-		t, err = time.Parse(XRefDateLayout, dayD.RateTime)
-		if err != nil {
-			return
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var dates []time.Time
+	var records []*XRefRawData
+	for idx, dayD := range c.XRefData.Data {
+		if dayD.RateTime < fromKey || dayD.RateTime > toKey || len(dayD.Rates) == 0 {
+			continue
 		}
-		d, err = c.Fetch(t)
+		t, err := time.Parse(XRefDateLayout, dayD.RateTime)
 		if err != nil {
-			return
+			return nil, err
 		}
-		rates[t] = d
+		dates = append(dates, t)
+		records = append(records, &c.XRefData.Data[idx])
+	}
+	parsed, err := c.parseRecords(records)
+	if err != nil {
+		return
+	}
+	rates = make(map[time.Time]ExchangeRates, len(records))
+	for idx, t := range dates {
+		rates[t] = parsed[idx]
 	}
 	return
-
 }
@@ -1,13 +1,21 @@
 package euroxref_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/xml"
+	"errors"
+	"fmt"
 	"github.com/exaroth/euroxref-konrad"
 	"io/ioutil"
+	"math"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
@@ -106,6 +114,30 @@ func testHandle(reqURL, reqMethod, reqBody *string) http.HandlerFunc {
 	}
 }
 
+func xmlHandle(response *euroxref.XRefRawResponse) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		data, err := xml.Marshal(response)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if _, err := w.Write(data); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+func routeHandle(routes map[string]*euroxref.XRefRawResponse) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		response, ok := routes[req.URL.Path]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		xmlHandle(response)(w, req)
+	}
+}
+
 func TestFetch(t *testing.T) {
 	tests := []struct {
 		Date      time.Time
@@ -207,7 +239,7 @@ func TestFetch(t *testing.T) {
 	for i, test := range tests {
 		var reqUrl, reqMethod, reqBody string
 		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
-		client := euroxref.New(test.Precision, 0)
+		client := euroxref.New(test.Precision, 0, euroxref.WithRoundedRates())
 		mock := MockServer(t, client.(*euroxref.Client), handler)
 		defer mock.Close()
 		res, err := client.Fetch(test.Date)
@@ -241,7 +273,7 @@ func TestConvert(t *testing.T) {
 			Amount:     10,
 			Precision:  4,
 			Currencies: [2]string{"CHF", "USD"},
-			Expected:   9.728,
+			Expected:   9.7282,
 			Err:        false,
 		},
 		{
@@ -249,23 +281,23 @@ func TestConvert(t *testing.T) {
 			Amount:     10,
 			Precision:  4,
 			Currencies: [2]string{"USD", "XYZ"},
-			Expected:   19.938,
+			Expected:   19.9379,
+			Err:        false,
+		},
+		{
+			Date:       time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC),
+			Amount:     10021000000.8999999,
+			Precision:  6,
+			Currencies: [2]string{"USD", "XYZ"},
+			Expected:   19979800567.286701,
 			Err:        false,
 		},
-		// {
-		// 	Date:       time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC),
-		// 	Amount:     10021000000.8999999,
-		// 	Precision:  6,
-		// 	Currencies: [2]string{"USD", "XYZ"},
-		// 	Expected:   19.93793,
-		// 	Err:        false,
-		// },
 		{
 			Date:       time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC),
 			Amount:     10,
 			Precision:  6,
 			Currencies: [2]string{"EUR", "USD"},
-			Expected:   10.03123,
+			Expected:   10.031231,
 			Err:        false,
 		},
 		{
@@ -289,7 +321,7 @@ func TestConvert(t *testing.T) {
 			Amount:     10,
 			Precision:  0,
 			Currencies: [2]string{"PLN", "CHF"},
-			Expected:   33,
+			Expected:   32.1,
 			Err:        false,
 		},
 		{
@@ -363,6 +395,78 @@ func TestConvert(t *testing.T) {
 	}
 }
 
+func TestAllowNegativeAmounts(t *testing.T) {
+	tests := []struct {
+		Amount     float64
+		Precision  uint
+		Currencies [2]string
+		Allow      bool
+		Expected   float64
+		Err        bool
+	}{
+		{
+			Amount:     -49.99,
+			Precision:  4,
+			Currencies: [2]string{"EUR", "USD"},
+			Allow:      true,
+			Expected:   -50.09,
+			Err:        false,
+		},
+		{
+			Amount:     -10,
+			Precision:  4,
+			Currencies: [2]string{"CHF", "USD"},
+			Allow:      true,
+			Expected:   -9.7282,
+			Err:        false,
+		},
+		{
+			Amount:     -10.555,
+			Precision:  2,
+			Currencies: [2]string{"USD", "USD"},
+			Allow:      true,
+			Expected:   -10.56,
+			Err:        false,
+		},
+		{
+			Amount:     -49.99,
+			Precision:  2,
+			Currencies: [2]string{"EUR", "USD"},
+			Allow:      false,
+			Err:        true,
+		},
+	}
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	for i, test := range tests {
+		client := euroxref.New(test.Precision, 0)
+		client.(*euroxref.Client).AllowNegativeAmounts = test.Allow
+		mock := MockServer(t, client.(*euroxref.Client), xmlHandle(testResponse))
+		res, err := client.Convert(test.Amount, test.Currencies[0], test.Currencies[1], date)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			mock.Close()
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if test.Expected != res {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.Expected, res, i)
+		}
+		// Result of negative amount mirrors the one of positive amount.
+		pos, err := client.Convert(-test.Amount, test.Currencies[0], test.Currencies[1], date)
+		mock.Close()
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if pos != -res {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", pos, -res, i)
+		}
+	}
+}
+
 func TestRoundingFloats(t *testing.T) {
 	tests := []struct {
 		Value     float64
@@ -434,6 +538,36 @@ func TestRoundingFloats(t *testing.T) {
 			Expected:  2131123123131.222,
 			Precision: 3,
 		},
+		{
+			Value:     19979799654.794411,
+			Expected:  19979799654.794411,
+			Precision: 6,
+		},
+		{
+			Value:     -0.4251,
+			Expected:  -0.43,
+			Precision: 2,
+		},
+		{
+			Value:     -0.4233,
+			Expected:  -0.42,
+			Precision: 2,
+		},
+		{
+			Value:     -10,
+			Expected:  -10,
+			Precision: 4,
+		},
+		{
+			Value:     -1.999999999,
+			Expected:  -1.999999999,
+			Precision: 10,
+		},
+		{
+			Value:     -0.000001,
+			Expected:  0,
+			Precision: 0,
+		},
 	}
 	for i, test := range tests {
 		result := euroxref.FloatToFixed(test.Value, test.Precision)
@@ -443,3 +577,1332 @@ func TestRoundingFloats(t *testing.T) {
 	}
 
 }
+
+func TestInverse(t *testing.T) {
+	tests := []struct {
+		Rates     euroxref.ExchangeRates
+		Precision int
+		Expected  euroxref.ExchangeRates
+		Err       bool
+	}{
+		{
+			Rates:     euroxref.ExchangeRates{{Currency: "USD", Rate: 1.002}, {Currency: "PLN", Rate: 4.5}},
+			Precision: 4,
+			Expected:  euroxref.ExchangeRates{{Currency: "USD", Rate: 0.998}, {Currency: "PLN", Rate: 0.2222}},
+			Err:       false,
+		},
+		{
+			Rates:     euroxref.ExchangeRates{{Currency: "EUR", Rate: 1}, {Currency: "CHF", Rate: 0.8}},
+			Precision: 2,
+			Expected:  euroxref.ExchangeRates{{Currency: "EUR", Rate: 1}, {Currency: "CHF", Rate: 1.25}},
+			Err:       false,
+		},
+		{
+			Rates:     euroxref.ExchangeRates{{Currency: "USD", Rate: 0}, {Currency: "CHF", Rate: 0.8}},
+			Precision: 2,
+			Expected:  euroxref.ExchangeRates{{Currency: "USD", Rate: math.Inf(1)}, {Currency: "CHF", Rate: 1.25}},
+			Err:       true,
+		},
+	}
+	for i, test := range tests {
+		res, err := test.Rates.InverseE(test.Precision)
+		if test.Err && err == nil {
+			t.Errorf("Want err != nil; got nil (i:%d)", i)
+		}
+		if !test.Err && err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(res, test.Expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+		if inv := test.Rates.Inverse(test.Precision); !reflect.DeepEqual(inv, test.Expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", inv, test.Expected, i)
+		}
+	}
+}
+
+func TestMapAll(t *testing.T) {
+	var reqUrl, reqMethod, reqBody string
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), testHandle(&reqUrl, &reqMethod, &reqBody))
+	defer mock.Close()
+	all, err := client.FetchAll()
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	expected := map[time.Time]map[string]float64{
+		time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC):  {"USD": 2.999999},
+		time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC): {"USD": 1.003123142, "PLN": 0.3211231231, "XYZ": 2.00001999},
+		time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC): {"USD": 1.002, "CHF": 1.03, "PLN": 0.321, "XYZ": 1.9999999},
+	}
+	if res := euroxref.MapAll(all); !reflect.DeepEqual(res, expected) {
+		t.Errorf("Values `%v` and `%v` are not equal", res, expected)
+	}
+	if res := euroxref.MapAll(nil); len(res) != 0 {
+		t.Errorf("Want empty map; got %v", res)
+	}
+}
+
+func TestNewValidated(t *testing.T) {
+	tests := []struct {
+		Precision       uint
+		RefreshInterval uint
+		Param           string
+		Err             bool
+	}{
+		{
+			Precision:       4,
+			RefreshInterval: 60,
+			Err:             false,
+		},
+		{
+			Precision:       euroxref.MaxPrecision,
+			RefreshInterval: euroxref.MaxRefreshInterval,
+			Err:             false,
+		},
+		{
+			Precision:       euroxref.MaxPrecision + 1,
+			RefreshInterval: 60,
+			Param:           "precision",
+			Err:             true,
+		},
+		{
+			Precision:       4,
+			RefreshInterval: euroxref.MaxRefreshInterval + 1,
+			Param:           "refreshInterval",
+			Err:             true,
+		},
+	}
+	for i, test := range tests {
+		client, err := euroxref.NewValidated(test.Precision, test.RefreshInterval)
+		if test.Err {
+			var vErr *euroxref.ValidationError
+			if !errors.As(err, &vErr) {
+				t.Errorf("Want *ValidationError; got %v (i:%d)", err, i)
+				continue
+			}
+			if vErr.Param != test.Param {
+				t.Errorf("Want param %s; got %s (i:%d)", test.Param, vErr.Param, i)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			}
+			if client == nil {
+				t.Errorf("Want client != nil; got nil (i:%d)", i)
+			}
+		}
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	var reqUrl, reqMethod, reqBody string
+	client := euroxref.NewClient(4, 0, euroxref.WithAmountPrecision(3))
+	// Fields are accessible without type assertion.
+	client.FallbackToPrevious = true
+	mock := MockServer(t, client, testHandle(&reqUrl, &reqMethod, &reqBody))
+	defer mock.Close()
+	var iface euroxref.XRefInterface = client
+	res, err := iface.Convert(10, "CHF", "USD", time.Date(2016, time.November, 12, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Errorf("Want err == nil; got %v", err)
+	}
+	if res != 9.7282 {
+		t.Errorf("Values `%v` and `%v` are not equal", res, 9.7282)
+	}
+	if client.HTTPClient == nil || client.HTTPClient.Timeout != euroxref.DefaultTimeout {
+		t.Errorf("Want HTTPClient limited by DefaultTimeout; got %v", client.HTTPClient)
+	}
+}
+
+func TestClientWithoutHTTPClient(t *testing.T) {
+	var reqUrl, reqMethod, reqBody string
+	mock := httptest.NewServer(testHandle(&reqUrl, &reqMethod, &reqBody))
+	defer mock.Close()
+	client := &euroxref.Client{SourceURL: mock.URL + "/stats/eurofxref/eurofxref-hist-90d.xml"}
+	res, err := client.Fetch(time.Date(2016, time.November, 9, 23, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Errorf("Want err == nil; got %v", err)
+	}
+	// Default client has to be limited, so that stalled server doesn't block retrieval forever.
+	if client.HTTPClient == nil || client.HTTPClient == http.DefaultClient || client.HTTPClient.Timeout != euroxref.DefaultTimeout {
+		t.Errorf("Want HTTPClient limited by DefaultTimeout; got %v", client.HTTPClient)
+	}
+	if len(res) != 1 || res[0].Rate != 2.999999 {
+		t.Errorf("Unexpected rates %v", res)
+	}
+}
+
+func TestConvertDetailed(t *testing.T) {
+	tests := []struct {
+		Date       time.Time
+		Amount     float64
+		Precision  uint
+		Currencies [2]string
+		Fallback   bool
+		RateDate   time.Time
+		Rate       float64
+		Exact      float64
+		Expected   float64
+		Err        bool
+	}{
+		{
+			Date:       time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount:     10,
+			Precision:  4,
+			Currencies: [2]string{"CHF", "USD"},
+			Rate:       0.9728,
+			Exact:      10 * 1.002 / 1.03,
+			Expected:   9.7282,
+			Err:        false,
+		},
+		{
+			Date:       time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount:     10.555,
+			Precision:  2,
+			Currencies: [2]string{"EUR", "PLN"},
+			Rate:       0.32,
+			Exact:      3.388155,
+			Expected:   3.39,
+			Err:        false,
+		},
+		{
+			Date:       time.Date(2016, time.November, 12, 23, 0, 0, 0, time.UTC),
+			Amount:     10,
+			Precision:  4,
+			Currencies: [2]string{"CHF", "USD"},
+			Fallback:   true,
+			RateDate:   time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Rate:       0.9728,
+			Exact:      10 * 1.002 / 1.03,
+			Expected:   9.7282,
+			Err:        false,
+		},
+		{
+			Date:       time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount:     10,
+			Precision:  4,
+			Currencies: [2]string{"BLE", "USD"},
+			Err:        true,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(test.Precision, 0)
+		client.(*euroxref.Client).FallbackToPrevious = test.Fallback
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		res, err := client.ConvertDetailed(test.Amount, test.Currencies[0], test.Currencies[1], test.Date)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		expected := euroxref.ConversionResult{
+			Amount: test.Amount,
+			Source: test.Currencies[0],
+			Target: test.Currencies[1],
+			Date:   time.Date(test.Date.Year(), test.Date.Month(), test.Date.Day(), 0, 0, 0, 0, time.UTC),
+			Rate:   test.Rate,
+			Exact:  test.Exact,
+			Result: test.Expected,
+		}
+		if !test.RateDate.IsZero() {
+			expected.Date = test.RateDate
+		}
+		if expected != res {
+			t.Errorf("Values `%+v` and `%+v` are not equal (i:%d)", expected, res, i)
+		}
+	}
+}
+
+func TestConvertMany(t *testing.T) {
+	var reqUrl, reqMethod, reqBody string
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), testHandle(&reqUrl, &reqMethod, &reqBody))
+	defer mock.Close()
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	items := []euroxref.ConversionRequest{
+		{Amount: 10, Target: "USD"},
+		{Amount: 10, Target: "BLE"},
+		{Amount: 10.555, Target: "pln"},
+	}
+	results, err := client.ConvertMany("chf", items, date)
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	if len(results) != len(items) {
+		t.Fatalf("Values `%v` and `%v` are not equal", len(results), len(items))
+	}
+	for i, item := range items {
+		want, wantErr := client.ConvertDetailed(item.Amount, "CHF", item.Target, date)
+		res := results[i]
+		if (wantErr != nil) != (res.Err != nil) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res.Err, wantErr, i)
+		}
+		if wantErr != nil {
+			continue
+		}
+		if res != want {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, want, i)
+		}
+	}
+
+	if _, err = client.ConvertMany("BLE", items, date); err == nil {
+		t.Errorf("Want err != nil for invalid source; got nil")
+	}
+}
+
+func TestFetchDay(t *testing.T) {
+	tests := []struct {
+		Date     time.Time
+		Fallback bool
+		Expected euroxref.DayRates
+		Err      bool
+	}{
+		{
+			Date:     time.Date(2016, time.November, 9, 23, 0, 0, 0, time.UTC),
+			Fallback: false,
+			Expected: euroxref.DayRates{
+				Date: time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+				Rates: euroxref.ExchangeRates{
+					{Currency: "EUR", Rate: 1},
+					{Currency: "USD", Rate: 2.999999},
+				},
+				Fallback: false,
+			},
+			Err: false,
+		},
+		{
+			Date:     time.Date(2016, time.November, 13, 23, 0, 0, 0, time.UTC),
+			Fallback: false,
+			Err:      true,
+		},
+		{
+			Date:     time.Date(2016, time.November, 13, 23, 0, 0, 0, time.UTC),
+			Fallback: true,
+			Expected: euroxref.DayRates{
+				Date: time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+				Rates: euroxref.ExchangeRates{
+					{Currency: "EUR", Rate: 1},
+					{Currency: "USD", Rate: 1.002},
+					{Currency: "CHF", Rate: 1.03},
+					{Currency: "PLN", Rate: 0.321},
+					{Currency: "XYZ", Rate: 1.9999999},
+				},
+				Fallback: true,
+			},
+			Err: false,
+		},
+		{
+			// 8th of November has no rates published so 9th can't fall back any further.
+			Date:     time.Date(2016, time.November, 8, 23, 0, 0, 0, time.UTC),
+			Fallback: true,
+			Err:      true,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0)
+		client.(*euroxref.Client).FallbackToPrevious = test.Fallback
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		res, err := client.FetchDay(test.Date)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if res.FetchedAt.IsZero() {
+			t.Errorf("Want FetchedAt to be set (i:%d)", i)
+		}
+		res.FetchedAt = time.Time{}
+		if !reflect.DeepEqual(test.Expected, res) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.Expected, res, i)
+		}
+	}
+}
+
+func TestFetchNearest(t *testing.T) {
+	tests := []struct {
+		Date            time.Time
+		MaxFallbackDays int
+		ExpectedDate    time.Time
+		Err             bool
+	}{
+		{
+			Date:            time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC),
+			MaxFallbackDays: 0,
+			ExpectedDate:    time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Err:             false,
+		},
+		{
+			Date:            time.Date(2016, time.November, 13, 23, 0, 0, 0, time.UTC),
+			MaxFallbackDays: 0,
+			ExpectedDate:    time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Err:             false,
+		},
+		{
+			Date:            time.Date(2016, time.November, 13, 23, 0, 0, 0, time.UTC),
+			MaxFallbackDays: 2,
+			ExpectedDate:    time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Err:             false,
+		},
+		{
+			Date:            time.Date(2016, time.November, 13, 23, 0, 0, 0, time.UTC),
+			MaxFallbackDays: 1,
+			Err:             true,
+		},
+		{
+			Date:            time.Date(2016, time.November, 8, 23, 0, 0, 0, time.UTC),
+			MaxFallbackDays: 0,
+			Err:             true,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0, euroxref.WithMaxFallbackDays(test.MaxFallbackDays))
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		res, date, err := client.FetchNearest(test.Date)
+		if test.Err {
+			var dErr *euroxref.ErrDateNotFound
+			if !errors.As(err, &dErr) {
+				t.Errorf("Want *ErrDateNotFound; got %v (i:%d)", err, i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !test.ExpectedDate.Equal(date) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.ExpectedDate, date, i)
+		}
+		if len(res) == 0 {
+			t.Errorf("Want rates; got none (i:%d)", i)
+		}
+	}
+	if _, err := euroxref.NewValidated(4, 0, euroxref.WithMaxFallbackDays(-1)); err == nil {
+		t.Errorf("Want err != nil for negative max fallback days; got nil")
+	}
+}
+
+func TestReady(t *testing.T) {
+	requests := 0
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		xmlHandle(testResponse)(w, req)
+	})
+	defer mock.Close()
+	if client.Ready() {
+		t.Errorf("Want client not to be ready before first fetch")
+	}
+	if requests != 0 {
+		t.Errorf("Want Ready not to fetch data; got %d requests", requests)
+	}
+	if _, err := client.Fetch(time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	if !client.Ready() {
+		t.Errorf("Want client to be ready after fetch")
+	}
+	empty := &euroxref.Client{XRefData: &euroxref.XRefRawResponse{}}
+	if empty.Ready() {
+		t.Errorf("Want client without any rates not to be ready")
+	}
+}
+
+func TestWarm(t *testing.T) {
+	tests := []struct {
+		Status   int
+		Response *euroxref.XRefRawResponse
+		Ready    bool
+	}{
+		{
+			Status:   http.StatusOK,
+			Response: testResponse,
+			Ready:    true,
+		},
+		{
+			Status:   http.StatusServiceUnavailable,
+			Response: testResponse,
+			Ready:    false,
+		},
+		{
+			Status:   http.StatusOK,
+			Response: &euroxref.XRefRawResponse{},
+			Ready:    false,
+		},
+	}
+	for i, test := range tests {
+		requests := 0
+		client := euroxref.New(4, 60)
+		mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+			requests++
+			if test.Status != http.StatusOK {
+				http.Error(w, http.StatusText(test.Status), test.Status)
+				return
+			}
+			xmlHandle(test.Response)(w, req)
+		})
+		err := client.Warm(context.Background())
+		if test.Ready && err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !test.Ready && err == nil {
+			t.Errorf("Want err != nil; got nil (i:%d)", i)
+		}
+		if client.Ready() != test.Ready {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", client.Ready(), test.Ready, i)
+		}
+		if test.Ready {
+			// Data retrieved by Warm is used within RefreshInterval.
+			if _, err = client.Fetch(time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)); err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			}
+			if requests != 1 {
+				t.Errorf("Values `%v` and `%v` are not equal (i:%d)", requests, 1, i)
+			}
+		}
+		mock.Close()
+	}
+}
+
+func TestContextCancellation(t *testing.T) {
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		// Simulate hanging endpoint.
+		select {
+		case <-req.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+	defer mock.Close()
+	calls := []func(ctx context.Context) error{
+		func(ctx context.Context) error {
+			_, err := client.FetchContext(ctx, date)
+			return err
+		},
+		func(ctx context.Context) error {
+			_, err := client.ConvertContext(ctx, 10, "USD", "PLN", date)
+			return err
+		},
+		func(ctx context.Context) error {
+			_, err := client.FetchAllContext(ctx)
+			return err
+		},
+	}
+	for i, call := range calls {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		err := call(ctx)
+		cancel()
+		if err != context.DeadlineExceeded {
+			t.Errorf("Want %v; got %v (i:%d)", context.DeadlineExceeded, err, i)
+		}
+	}
+}
+
+func TestConcurrentConvert(t *testing.T) {
+	for _, refreshInterval := range []uint{0, 60} {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		var mu sync.Mutex
+		client := euroxref.New(4, refreshInterval)
+		mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			handler(w, req)
+		})
+		defer mock.Close()
+		var wg sync.WaitGroup
+		errs := make(chan error, 50)
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				res, err := client.Convert(10, "CHF", "USD", time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC))
+				if err == nil && res != 9.7282 {
+					err = fmt.Errorf("Values `%v` and `%v` are not equal", 9.7282, res)
+				}
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				t.Errorf("Want err == nil; got %v (refresh interval: %d)", err, refreshInterval)
+			}
+		}
+	}
+}
+
+func TestListCurrencies(t *testing.T) {
+	tests := []struct {
+		Date     time.Time
+		Expected []string
+		Err      bool
+	}{
+		{
+			Date:     time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Expected: []string{"CHF", "EUR", "PLN", "USD", "XYZ"},
+			Err:      false,
+		},
+		{
+			Date:     time.Date(2016, time.November, 9, 23, 0, 0, 0, time.UTC),
+			Expected: []string{"EUR", "USD"},
+			Err:      false,
+		},
+		{
+			Date: time.Date(2016, time.November, 8, 23, 0, 0, 0, time.UTC),
+			Err:  true,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0)
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		res, err := client.ListCurrencies(test.Date)
+		if test.Err {
+			var dErr *euroxref.ErrDateNotFound
+			if !errors.As(err, &dErr) {
+				t.Errorf("Want *ErrDateNotFound; got %v (i:%d)", err, i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(test.Expected, res) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.Expected, res, i)
+		}
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	var reqUrl, reqMethod, reqBody string
+	handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), handler)
+	defer mock.Close()
+
+	_, err := client.Convert(10, "USD", "PLN", time.Date(2016, time.November, 8, 23, 0, 0, 0, time.UTC))
+	var dErr *euroxref.ErrDateNotFound
+	if !errors.As(err, &dErr) {
+		t.Errorf("Want *ErrDateNotFound; got %v", err)
+	} else if !dErr.Date.Equal(time.Date(2016, time.November, 8, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Want date 2016-11-08; got %v", dErr.Date)
+	}
+
+	_, err = client.Convert(10, "BLE", "USD", time.Date(2016, time.November, 9, 23, 0, 0, 0, time.UTC))
+	var cErr *euroxref.ErrCurrencyNotFound
+	if !errors.As(err, &cErr) {
+		t.Fatalf("Want *ErrCurrencyNotFound; got %v", err)
+	}
+	if !reflect.DeepEqual([]string{"BLE", "USD"}, cErr.Currencies) || !reflect.DeepEqual([]string{"USD"}, cErr.Available) {
+		t.Errorf("Unexpected error details %+v", cErr)
+	}
+	if expected := "Invalid currencies selected: BLE, USD. List of available currency rates: USD for 2016-11-09"; err.Error() != expected {
+		t.Errorf("Values `%s` and `%s` are not equal", expected, err.Error())
+	}
+}
+
+func TestFallbackToPrevious(t *testing.T) {
+	tests := []struct {
+		Date     time.Time
+		Fallback bool
+		Expected float64
+		Err      bool
+	}{
+		{
+			Date:     time.Date(2016, time.November, 12, 23, 0, 0, 0, time.UTC), // Saturday
+			Fallback: false,
+			Err:      true,
+		},
+		{
+			Date:     time.Date(2016, time.November, 12, 23, 0, 0, 0, time.UTC),
+			Fallback: true,
+			Expected: 3.2036,
+			Err:      false,
+		},
+		{
+			// There's no data published before the requested date.
+			Date:     time.Date(2016, time.November, 8, 23, 0, 0, 0, time.UTC),
+			Fallback: true,
+			Err:      true,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0)
+		client.(*euroxref.Client).FallbackToPrevious = test.Fallback
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		res, err := client.Convert(10, "USD", "PLN", test.Date)
+		if test.Err {
+			var dErr *euroxref.ErrDateNotFound
+			if !errors.As(err, &dErr) {
+				t.Errorf("Want *ErrDateNotFound; got %v (i:%d)", err, i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if res != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+}
+
+func TestCaseInsensitiveCurrencies(t *testing.T) {
+	tests := []struct {
+		Lower [2]string
+		Upper [2]string
+	}{
+		{Lower: [2]string{"chf", "usd"}, Upper: [2]string{"CHF", "USD"}},
+		{Lower: [2]string{"eur", "Pln"}, Upper: [2]string{"EUR", "PLN"}},
+		{Lower: [2]string{"usd", "eur"}, Upper: [2]string{"USD", "EUR"}},
+	}
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0)
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		lower, err := client.Convert(10, test.Lower[0], test.Lower[1], date)
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		upper, err := client.Convert(10, test.Upper[0], test.Upper[1], date)
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if lower != upper {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", lower, upper, i)
+		}
+	}
+}
+
+func TestFetchWithDate(t *testing.T) {
+	tests := []struct {
+		Date         time.Time
+		Fallback     bool
+		ExpectedDate time.Time
+		Err          bool
+	}{
+		{
+			Date:         time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC),
+			Fallback:     false,
+			ExpectedDate: time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Err:          false,
+		},
+		{
+			Date:     time.Date(2016, time.November, 13, 10, 0, 0, 0, time.UTC),
+			Fallback: false,
+			Err:      true,
+		},
+		{
+			Date:         time.Date(2016, time.November, 13, 10, 0, 0, 0, time.UTC),
+			Fallback:     true,
+			ExpectedDate: time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Err:          false,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0)
+		client.(*euroxref.Client).FallbackToPrevious = test.Fallback
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		res, date, err := client.FetchWithDate(test.Date)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !test.ExpectedDate.Equal(date) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.ExpectedDate, date, i)
+		}
+		expected, _ := client.Fetch(test.Date)
+		if !reflect.DeepEqual(expected, res) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", expected, res, i)
+		}
+	}
+}
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		Currency string
+		Date     time.Time
+		Expected float64
+		Err      bool
+	}{
+		{
+			Currency: "USD",
+			Date:     time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC),
+			Expected: 1.003123142,
+		},
+		{
+			Currency: "eur",
+			Date:     time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC),
+			Expected: 1,
+		},
+		{
+			Currency: "CHF",
+			Date:     time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC),
+			Err:      true,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0)
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		res, err := client.Rate(test.Currency, test.Date)
+		eurRes, eurErr := client.EURRate(test.Currency, test.Date)
+		if test.Err {
+			var cErr *euroxref.ErrCurrencyNotFound
+			if !errors.As(err, &cErr) {
+				t.Errorf("Want *ErrCurrencyNotFound; got %v (i:%d)", err, i)
+			}
+			if !errors.As(eurErr, &cErr) {
+				t.Errorf("Want *ErrCurrencyNotFound; got %v (i:%d)", eurErr, i)
+			}
+			continue
+		}
+		if err != nil || eurErr != nil {
+			t.Errorf("Want err == nil; got %v, %v (i:%d)", err, eurErr, i)
+		}
+		if res != test.Expected || eurRes != test.Expected {
+			t.Errorf("Values `%v`, `%v` and `%v` are not equal (i:%d)", res, eurRes, test.Expected, i)
+		}
+	}
+}
+
+func TestConvertPrec(t *testing.T) {
+	tests := []struct {
+		Amount     float64
+		Currencies [2]string
+		Prec       int
+		Expected   float64
+		Err        bool
+	}{
+		{
+			Amount:     10.4,
+			Currencies: [2]string{"EUR", "USD"},
+			Prec:       0,
+			Expected:   10,
+		},
+		{
+			Amount:     10.4,
+			Currencies: [2]string{"EUR", "USD"},
+			Prec:       2,
+			Expected:   10.42,
+		},
+		{
+			Amount:     10.4,
+			Currencies: [2]string{"EUR", "USD"},
+			Prec:       3,
+			Expected:   10.421,
+		},
+		{
+			Amount:     10,
+			Currencies: [2]string{"CHF", "USD"},
+			Prec:       0,
+			Expected:   10,
+		},
+		{
+			Amount:     10.5,
+			Currencies: [2]string{"USD", "USD"},
+			Prec:       0,
+			Expected:   11,
+		},
+		{
+			Amount:     10,
+			Currencies: [2]string{"CHF", "USD"},
+			Prec:       -1,
+			Err:        true,
+		},
+		{
+			Amount:     10,
+			Currencies: [2]string{"CHF", "USD"},
+			Prec:       euroxref.MaxPrecision + 1,
+			Err:        true,
+		},
+	}
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0)
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		res, err := client.ConvertPrec(test.Amount, test.Currencies[0], test.Currencies[1], date, test.Prec)
+		if test.Err {
+			var vErr *euroxref.ValidationError
+			if !errors.As(err, &vErr) {
+				t.Errorf("Want *ValidationError; got %v (i:%d)", err, i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if res != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+}
+
+func TestFetchRange(t *testing.T) {
+	tests := []struct {
+		From     time.Time
+		To       time.Time
+		Expected []time.Time
+		Err      bool
+	}{
+		{
+			From: time.Date(2016, time.November, 8, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC),
+			Expected: []time.Time{
+				time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+				time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			},
+			Err: false,
+		},
+		{
+			From:     time.Date(2016, time.December, 1, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.December, 31, 0, 0, 0, 0, time.UTC),
+			Expected: []time.Time{},
+			Err:      false,
+		},
+		{
+			From: time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Err:  true,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0)
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		res, err := client.FetchRange(test.From, test.To)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if len(res) != len(test.Expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", len(res), len(test.Expected), i)
+		}
+		for _, date := range test.Expected {
+			expected, _ := client.Fetch(date)
+			if !reflect.DeepEqual(res[date], expected) {
+				t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res[date], expected, i)
+			}
+		}
+	}
+}
+
+func TestFetchAll(t *testing.T) {
+	var reqUrl, reqMethod, reqBody string
+	handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), handler)
+	defer mock.Close()
+	res, err := client.FetchAll()
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	if len(res) != 3 {
+		t.Errorf("Values `%v` and `%v` are not equal", len(res), 3)
+	}
+	if _, ok := res[time.Date(2016, time.November, 8, 0, 0, 0, 0, time.UTC)]; ok {
+		t.Errorf("Want day without rates to be skipped")
+	}
+}
+
+func TestDateIndexRefresh(t *testing.T) {
+	responses := []*euroxref.XRefRawResponse{testResponse, revisedResponse}
+	requests := 0
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		xmlHandle(responses[requests%len(responses)])(w, req)
+		requests++
+	})
+	defer mock.Close()
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	for i, expected := range []float64{1.002, 1.1} {
+		res, err := client.Rate("USD", date)
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if res != expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, expected, i)
+		}
+	}
+	// Responses alternate on every refresh and only the first one contains 2016-11-10.
+	if _, err := client.Fetch(time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Errorf("Want err == nil; got %v", err)
+	}
+	if _, err := client.Fetch(time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Errorf("Want err != nil; got nil")
+	}
+}
+
+func TestFullPrecisionRates(t *testing.T) {
+	var reqUrl, reqMethod, reqBody string
+	handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), handler)
+	defer mock.Close()
+	res, err := client.Fetch(time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	expected := euroxref.ExchangeRates{
+		{Currency: "USD", Rate: 1.003123142},
+		{Currency: "PLN", Rate: 0.3211231231},
+		{Currency: "XYZ", Rate: 2.00001999},
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("Values `%v` and `%v` are not equal", res, expected)
+	}
+}
+
+func TestClose(t *testing.T) {
+	var reqUrl, reqMethod, reqBody string
+	handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+	client := euroxref.New(4, 60)
+	mock := MockServer(t, client.(*euroxref.Client), handler)
+	defer mock.Close()
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	if _, err := client.Fetch(date); err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Errorf("Want err == nil; got %v", err)
+	}
+	if _, err := client.Fetch(date); !errors.Is(err, euroxref.ErrClientClosed) {
+		t.Errorf("Want ErrClientClosed; got %v", err)
+	}
+	if err := client.Refresh(context.Background()); !errors.Is(err, euroxref.ErrClientClosed) {
+		t.Errorf("Want ErrClientClosed; got %v", err)
+	}
+}
+
+func TestConnectionReuseOnDecodeError(t *testing.T) {
+	var mu sync.Mutex
+	connections := 0
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("<Envelope><Cube></Envelope>"))
+		w.Write(make([]byte, 4*1024*1024))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			connections++
+			mu.Unlock()
+		}
+	}
+	server.Start()
+	defer server.Close()
+	client := euroxref.New(4, 0, euroxref.WithTransport(&MockedTransport{
+		Transport: http.Transport{
+			Proxy: func(req *http.Request) (*url.URL, error) {
+				return url.Parse(server.URL)
+			},
+		},
+	}))
+	for i := 0; i < 3; i++ {
+		if _, err := client.Fetch(time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)); err == nil {
+			t.Errorf("Want err != nil; got nil (i:%d)", i)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if connections != 1 {
+		t.Errorf("Values `%v` and `%v` are not equal", connections, 1)
+	}
+}
+
+func TestDataAge(t *testing.T) {
+	var reqUrl, reqMethod, reqBody string
+	handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+	client := euroxref.New(4, 60)
+	mock := MockServer(t, client.(*euroxref.Client), handler)
+	defer mock.Close()
+	if !client.LastFetched().IsZero() || client.DataAge() != 0 {
+		t.Errorf("Want no fetch time before first fetch; got %v", client.LastFetched())
+	}
+	before := time.Now()
+	latest, err := client.LatestDate()
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	expected := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	if !latest.Equal(expected) {
+		t.Errorf("Values `%v` and `%v` are not equal", latest, expected)
+	}
+	if fetched := client.LastFetched(); fetched.Before(before) || fetched.After(time.Now()) {
+		t.Errorf("Want fetch time between %v and now; got %v", before, fetched)
+	}
+	if age := client.DataAge(); age <= 0 || age > time.Since(before) {
+		t.Errorf("Want data age below %v; got %v", time.Since(before), age)
+	}
+}
+
+func TestFetchHooks(t *testing.T) {
+	client := euroxref.New(4, 60)
+	c := client.(*euroxref.Client)
+	c.MaxRetries = 1
+	c.RetryBackoff = time.Millisecond
+	requests := 0
+	mock := MockServer(t, c, func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		if requests == 1 {
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		xmlHandle(testResponse)(w, req)
+	})
+	defer mock.Close()
+
+	var started []string
+	var errs []error
+	hits := 0
+	c.OnFetchStart = func(url string) {
+		started = append(started, url)
+	}
+	c.OnFetchDone = func(url string, dur time.Duration, err error) {
+		if url != c.SourceURL {
+			t.Errorf("Values `%v` and `%v` are not equal", url, c.SourceURL)
+		}
+		if dur < 0 {
+			t.Errorf("Want dur >= 0; got %v", dur)
+		}
+		errs = append(errs, err)
+	}
+	c.OnCacheHit = func() {
+		hits++
+	}
+
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if _, err := client.Fetch(date); err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+	}
+	if len(started) != 2 || len(errs) != 2 {
+		t.Fatalf("Want 2 fetches; got %d started and %d done", len(started), len(errs))
+	}
+	var sErr *euroxref.StatusError
+	if !errors.As(errs[0], &sErr) {
+		t.Errorf("Want *StatusError; got %v", errs[0])
+	}
+	if errs[1] != nil {
+		t.Errorf("Want err == nil; got %v", errs[1])
+	}
+	if hits != 2 {
+		t.Errorf("Values `%v` and `%v` are not equal", hits, 2)
+	}
+}
+
+func TestMalformedRates(t *testing.T) {
+	malformed := &euroxref.XRefRawResponse{
+		Data: []euroxref.XRefRawData{
+			{
+				RateTime: "2016-11-11",
+				Rates: []euroxref.RawExchangeRate{
+					{Currency: "USD", Rate: "1,002"},
+					{Currency: "CHF", Rate: "1.03"},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		Skip     bool
+		Expected euroxref.ExchangeRates
+		Skipped  int
+		Err      bool
+	}{
+		{
+			Skip: false,
+			Err:  true,
+		},
+		{
+			Skip:     true,
+			Expected: euroxref.ExchangeRates{{Currency: "CHF", Rate: 1.03}},
+			Skipped:  1,
+			Err:      false,
+		},
+	}
+	for i, test := range tests {
+		var skipped []*euroxref.ParseError
+		client := euroxref.New(4, 0)
+		client.(*euroxref.Client).SkipMalformed = test.Skip
+		client.(*euroxref.Client).OnMalformed = func(err *euroxref.ParseError) {
+			skipped = append(skipped, err)
+		}
+		mock := MockServer(t, client.(*euroxref.Client), xmlHandle(malformed))
+		res, err := client.Fetch(time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC))
+		mock.Close()
+		if test.Err {
+			var pErr *euroxref.ParseError
+			if !errors.As(err, &pErr) {
+				t.Errorf("Want *ParseError; got %v (i:%d)", err, i)
+				continue
+			}
+			if pErr.RateTime != "2016-11-11" || pErr.Currency != "USD" || pErr.Value != "1,002" {
+				t.Errorf("Unexpected parse error details: %+v (i:%d)", pErr, i)
+			}
+			if !errors.Is(err, strconv.ErrSyntax) {
+				t.Errorf("Want strconv.ErrSyntax to be wrapped; got %v (i:%d)", err, i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(res, test.Expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+		if len(skipped) != test.Skipped {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", len(skipped), test.Skipped, i)
+		}
+	}
+}
+
+func TestRecheckRecentDate(t *testing.T) {
+	updated := &euroxref.XRefRawResponse{
+		Data: append([]euroxref.XRefRawData{
+			{RateTime: "2016-11-14", Rates: []euroxref.RawExchangeRate{{Currency: "USD", Rate: "1.07"}}},
+		}, testResponse.Data...),
+	}
+	requests := 0
+	published := false
+	client := euroxref.New(4, 3600)
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		if published {
+			xmlHandle(updated)(w, req)
+			return
+		}
+		xmlHandle(testResponse)(w, req)
+	})
+	defer mock.Close()
+	if _, err := client.Fetch(time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	// Dates in the future can't have been published yet.
+	if _, err := client.Fetch(time.Now().AddDate(0, 0, 2)); err == nil {
+		t.Errorf("Want err != nil; got nil")
+	}
+	if requests != 1 {
+		t.Errorf("Want 1 request; got %d", requests)
+	}
+	published = true
+	res, err := client.Fetch(time.Date(2016, time.November, 14, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Errorf("Want err == nil; got %v", err)
+	}
+	expected := euroxref.ExchangeRates{{Currency: "USD", Rate: 1.07}}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("Values `%v` and `%v` are not equal", res, expected)
+	}
+	if requests != 2 {
+		t.Errorf("Want 2 requests; got %d", requests)
+	}
+	// Data which was just refreshed isn't rechecked again.
+	for i := 0; i < 2; i++ {
+		var dErr *euroxref.ErrDateNotFound
+		if _, err := client.Fetch(time.Date(2016, time.November, 15, 0, 0, 0, 0, time.UTC)); !errors.As(err, &dErr) {
+			t.Errorf("Want *ErrDateNotFound; got %v", err)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("Want 2 requests; got %d", requests)
+	}
+}
+
+func TestDuplicateDates(t *testing.T) {
+	duplicated := &euroxref.XRefRawResponse{
+		Data: []euroxref.XRefRawData{
+			{
+				RateTime: "2016-11-11",
+				Rates:    []euroxref.RawExchangeRate{{Currency: "USD", Rate: "1.002"}},
+			},
+			{
+				RateTime: "2016-11-10",
+				Rates:    []euroxref.RawExchangeRate{{Currency: "USD", Rate: "1.003"}},
+			},
+			{
+				RateTime: "2016-11-11",
+				Rates:    []euroxref.RawExchangeRate{{Currency: "USD", Rate: "1.1"}, {Currency: "CHF", Rate: "1.03"}},
+			},
+		},
+	}
+	raw, err := xml.Marshal(duplicated)
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	expected := euroxref.ExchangeRates{{Currency: "USD", Rate: 1.1}, {Currency: "CHF", Rate: 1.03}}
+	for i, load := range []bool{false, true} {
+		client := euroxref.New(4, 60)
+		mock := MockServer(t, client.(*euroxref.Client), xmlHandle(duplicated))
+		if load {
+			if err := client.LoadFrom(bytes.NewReader(raw)); err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			}
+		}
+		res, err := client.Fetch(date)
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		all, err := client.FetchAll()
+		mock.Close()
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(res, expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, expected, i)
+		}
+		if !reflect.DeepEqual(all[date], expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", all[date], expected, i)
+		}
+		if len(all) != 2 {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", len(all), 2, i)
+		}
+	}
+}
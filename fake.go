@@ -0,0 +1,38 @@
+package euroxref
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// NewFakeClient returns client serving given exchange rates from memory instead of retrieving them from ECB,
+// which is meant for tests of code depending on XRefClient. Rates are relative to EUR same as ECB data,
+// only date part of the keys is used.
+func NewFakeClient(rates map[time.Time]ExchangeRates, precision uint, opts ...Option) XRefClient {
+	dates := make([]time.Time, 0, len(rates))
+	for date := range rates {
+		dates = append(dates, date)
+	}
+	// ECB lists the latest days first.
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].After(dates[j])
+	})
+	data := &XRefRawResponse{Data: make([]XRefRawData, 0, len(dates))}
+	for _, date := range dates {
+		record := XRefRawData{RateTime: date.Format(XRefDateLayout), Rates: []RawExchangeRate{}}
+		for _, rate := range rates[date] {
+			record.Rates = append(record.Rates, RawExchangeRate{
+				Currency: rate.Currency,
+				Rate:     strconv.FormatFloat(rate.Rate, 'f', -1, 64),
+			})
+		}
+		data.Data = append(data.Data, record)
+	}
+	c := New(precision, 0, opts...).(*Client)
+	// Data never expires so it's never requested from SourceURL.
+	c.XRefData = data
+	c.lastFetched = time.Now()
+	c.cachedUntil = neverExpires
+	return c
+}
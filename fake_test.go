@@ -0,0 +1,93 @@
+package euroxref_test
+
+import (
+	"errors"
+	"github.com/exaroth/euroxref-konrad"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// failingTransport rejects every request so that tests can make sure no data is retrieved.
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, errors.New("Unexpected request")
+}
+
+// convertTotal represents code depending on the client which is tested using a fake.
+func convertTotal(client euroxref.XRefClient, amounts []float64, source, target string, t time.Time) (total float64, err error) {
+	for _, amount := range amounts {
+		var res float64
+		res, err = client.Convert(amount, source, target, t)
+		if err != nil {
+			return
+		}
+		total += res
+	}
+	return
+}
+
+func TestFakeClient(t *testing.T) {
+	rates := map[time.Time]euroxref.ExchangeRates{
+		time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC): {
+			{Currency: "USD", Rate: 1.002},
+			{Currency: "CHF", Rate: 1.03},
+		},
+		time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC): {
+			{Currency: "USD", Rate: 1.1},
+		},
+	}
+	client := euroxref.NewFakeClient(rates, 4, euroxref.WithTransport(failingTransport{}))
+	tests := []struct {
+		Date       time.Time
+		Currencies [2]string
+		Expected   float64
+		Err        bool
+	}{
+		{
+			Date:       time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Currencies: [2]string{"CHF", "USD"},
+			Expected:   9.7282,
+			Err:        false,
+		},
+		{
+			Date:       time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Currencies: [2]string{"EUR", "USD"},
+			Expected:   11,
+			Err:        false,
+		},
+		{
+			Date:       time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Currencies: [2]string{"EUR", "CHF"},
+			Err:        true,
+		},
+		{
+			Date:       time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+			Currencies: [2]string{"EUR", "USD"},
+			Err:        true,
+		},
+	}
+	for i, test := range tests {
+		total, err := convertTotal(client, []float64{5, 5}, test.Currencies[0], test.Currencies[1], test.Date)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if total != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", total, test.Expected, i)
+		}
+	}
+	all, err := client.FetchAll()
+	if err != nil {
+		t.Errorf("Want err == nil; got %v", err)
+	}
+	if len(all) != len(rates) {
+		t.Errorf("Values `%v` and `%v` are not equal", len(all), len(rates))
+	}
+}
@@ -0,0 +1,196 @@
+package euroxref
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// recentFeedDays is the number of days covered by the 90 day feed.
+const recentFeedDays = 90
+
+// HistoryMode selects ECB dataset used by the client.
+type HistoryMode int
+
+const (
+	// Last90Days uses feed with exchange rates from the last 90 days, it's the default.
+	Last90Days HistoryMode = iota
+	// FullHistory uses feed with all exchange rates published since 1999.
+	FullHistory
+)
+
+// WithHistoryMode selects ECB dataset used by Fetch, Convert, FetchAll and other methods of the client.
+// Full history covers much longer period, but is significantly larger to download and parse.
+func WithHistoryMode(mode HistoryMode) Option {
+	return func(c *Client) {
+		switch mode {
+		case FullHistory:
+			c.SourceURL = historicalReferenceRatesUrl
+		default:
+			c.SourceURL = exchangeReferenceRatesUrl
+		}
+	}
+}
+
+// FetchHistorical retrieves exchange rates for given day from the full history feed,
+// regardless of the dataset selected for the client. Full history data is cached separately.
+func (c *Client) FetchHistorical(t time.Time) (rates ExchangeRates, err error) {
+	if c.SourceURL == historicalReferenceRatesUrl {
+		return c.Fetch(t)
+	}
+	return c.feedClient(historicalReferenceRatesUrl).Fetch(t)
+}
+
+// FetchLatest retrieves the most recent exchange rates from ECB daily feed along with the date they apply to.
+// Daily feed is published around 16:00 CET, so unlike the 90 day one it includes rates of the present day
+// once they're available. Daily feed data is cached separately.
+func (c *Client) FetchLatest() (rates ExchangeRates, date time.Time, err error) {
+	fc := c.feedClient(dailyReferenceRatesUrl)
+	err = fc.fetchXML(context.Background())
+	if err != nil {
+		return
+	}
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+	record := fc.latestRecord()
+	if record == nil {
+		return rates, date, errors.New("No exchange rate data available")
+	}
+	date, err = time.Parse(XRefDateLayout, record.RateTime)
+	if err != nil {
+		return
+	}
+	rates, err = fc.parseRecord(record)
+	return
+}
+
+// WithRevisedRates makes client download full history feed alongside the 90 day one
+// and prefer its values for dates present in both, as ECB occasionally revises published rates.
+// Available dates are still limited to ones present in the 90 day feed.
+func WithRevisedRates() Option {
+	return func(c *Client) {
+		c.preferRevised = true
+	}
+}
+
+// overlayFeed replaces days in base with the ones from revised for all dates present in both.
+func overlayFeed(base, revised *XRefRawResponse) *XRefRawResponse {
+	revisedDays := make(map[string]XRefRawData)
+	for _, dayD := range revised.Data {
+		revisedDays[dayD.RateTime] = dayD
+	}
+	merged := &XRefRawResponse{XMLName: base.XMLName}
+	for _, dayD := range base.Data {
+		if revisedD, ok := revisedDays[dayD.RateTime]; ok {
+			dayD = revisedD
+		}
+		merged.Data = append(merged.Data, dayD)
+	}
+	return merged
+}
+
+// WithDailyRates makes client download ECB daily feed alongside the selected dataset and merge its rates in,
+// so that rates of the present day are served by Fetch, Convert, FetchAll and other methods of the client
+// as soon as they're published, even though historical feeds don't include them yet.
+// If daily feed covers date already present in the dataset, its rates take precedence.
+func WithDailyRates() Option {
+	return func(c *Client) {
+		c.mergeDaily = true
+	}
+}
+
+// mergeDailyFeed returns base with days from daily feed added, replacing days of base with the same date.
+// Daily feed covers the most recent date, so its days are put first same as in ECB feeds.
+func mergeDailyFeed(base, daily *XRefRawResponse) *XRefRawResponse {
+	dailyDays := make(map[string]bool)
+	for _, dayD := range daily.Data {
+		dailyDays[dayD.RateTime] = true
+	}
+	merged := &XRefRawResponse{XMLName: base.XMLName}
+	merged.Data = append(merged.Data, daily.Data...)
+	for _, dayD := range base.Data {
+		if !dailyDays[dayD.RateTime] {
+			merged.Data = append(merged.Data, dayD)
+		}
+	}
+	return merged
+}
+
+// SmartConvert converts amount same as Convert, but selects ECB feed covering requested date:
+// daily feed for present day, 90 day feed for recent dates and full history feed for older ones.
+// If selected feed has no data for the date the next larger one is consulted,
+// *ErrDateNotFound is returned only if none of them covers it. Data of feeds other than SourceURL is cached separately.
+func (c *Client) SmartConvert(amount float64, source, target string, t time.Time) (result float64, err error) {
+	date, _ := c.ResolveDate(t)
+	// Present day is resolved in client Location, or UTC if it's not set, regardless of local time zone.
+	today, _ := c.ResolveDate(time.Now().UTC())
+	var urls []string
+	switch {
+	case !date.Before(today):
+		urls = []string{dailyReferenceRatesUrl, exchangeReferenceRatesUrl}
+	case date.After(today.AddDate(0, 0, -recentFeedDays)):
+		urls = []string{exchangeReferenceRatesUrl, historicalReferenceRatesUrl}
+	default:
+		urls = []string{historicalReferenceRatesUrl}
+	}
+	for _, url := range urls {
+		fc := c
+		// Data of the client itself is used if it already retrieves selected feed.
+		if c.SourceURL != url {
+			fc = c.feedClient(url)
+		}
+		result, err = fc.Convert(amount, source, target, t)
+		var dErr *ErrDateNotFound
+		if !errors.As(err, &dErr) {
+			return
+		}
+	}
+	return
+}
+
+// feedClient returns client retrieving data from feed under url, sharing configuration of c.
+func (c *Client) feedClient(url string) *Client {
+	c.feedsMu.Lock()
+	defer c.feedsMu.Unlock()
+	if fc, ok := c.feeds[url]; ok {
+		return fc
+	}
+	fc := &Client{
+		HTTPClient:           c.httpClient(),
+		SourceURL:            url,
+		RefreshInterval:      c.RefreshInterval,
+		FallbackToPrevious:   c.FallbackToPrevious,
+		Location:             c.Location,
+		MaxRetries:           c.MaxRetries,
+		RetryBackoff:         c.RetryBackoff,
+		ServeStale:           c.ServeStale,
+		StrictCurrencies:     c.StrictCurrencies,
+		AllowNegativeAmounts: c.AllowNegativeAmounts,
+		UseCurrencyDecimals:  c.UseCurrencyDecimals,
+		CacheDir:             c.CacheDir,
+		CacheTTL:             c.CacheTTL,
+		Cache:                c.Cache,
+		OnFetchStart:         c.OnFetchStart,
+		OnFetchDone:          c.OnFetchDone,
+		OnCacheHit:           c.OnCacheHit,
+		SkipMalformed:        c.SkipMalformed,
+		OnMalformed:          c.OnMalformed,
+		StrictXML:            c.StrictXML,
+		prec:                 c.prec,
+		maxFallbackDays:      c.maxFallbackDays,
+		jumpThreshold:        c.jumpThreshold,
+		onJump:               c.onJump,
+		amountPrec:           c.amountPrec,
+		roundingIncrement:    c.roundingIncrement,
+		roundingMode:         c.roundingMode,
+		roundRates:           c.roundRates,
+		limiter:              c.limiter,
+		spreadSide:           c.spreadSide,
+		parseWorkers:         c.parseWorkers,
+	}
+	if c.feeds == nil {
+		c.feeds = make(map[string]*Client)
+	}
+	c.feeds[url] = fc
+	return fc
+}
@@ -0,0 +1,540 @@
+package euroxref_test
+
+import (
+	"errors"
+	"github.com/exaroth/euroxref-konrad"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var revisedResponse = &euroxref.XRefRawResponse{
+	Data: []euroxref.XRefRawData{
+		{
+			RateTime: "2016-11-11",
+			Rates: []euroxref.RawExchangeRate{
+				{
+					Currency: "USD",
+					Rate:     "1.1",
+				},
+				{
+					Currency: "CHF",
+					Rate:     "1.03",
+				},
+			},
+		},
+		{
+			RateTime: "2016-01-04",
+			Rates: []euroxref.RawExchangeRate{
+				{
+					Currency: "USD",
+					Rate:     "1.09",
+				},
+			},
+		},
+	},
+}
+
+func TestRevisedRates(t *testing.T) {
+	tests := []struct {
+		Revised  bool
+		Date     time.Time
+		Expected euroxref.ExchangeRates
+		Err      bool
+	}{
+		{
+			Revised: false,
+			Date:    time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Expected: euroxref.ExchangeRates{
+				{Currency: "USD", Rate: 1.002},
+				{Currency: "CHF", Rate: 1.03},
+				{Currency: "PLN", Rate: 0.321},
+				{Currency: "XYZ", Rate: 1.9999999},
+			},
+			Err: false,
+		},
+		{
+			Revised: true,
+			Date:    time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Expected: euroxref.ExchangeRates{
+				{Currency: "USD", Rate: 1.1},
+				{Currency: "CHF", Rate: 1.03},
+			},
+			Err: false,
+		},
+		{
+			Revised: true,
+			Date:    time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Expected: euroxref.ExchangeRates{
+				{Currency: "USD", Rate: 1.003123142},
+				{Currency: "PLN", Rate: 0.3211231231},
+				{Currency: "XYZ", Rate: 2.00001999},
+			},
+			Err: false,
+		},
+		{
+			Revised: true,
+			Date:    time.Date(2016, time.January, 4, 0, 0, 0, 0, time.UTC),
+			Err:     true,
+		},
+	}
+	handler := routeHandle(map[string]*euroxref.XRefRawResponse{
+		"/stats/eurofxref/eurofxref-hist-90d.xml": testResponse,
+		"/stats/eurofxref/eurofxref-hist.xml":     revisedResponse,
+	})
+	for i, test := range tests {
+		var opts []euroxref.Option
+		if test.Revised {
+			opts = append(opts, euroxref.WithRevisedRates())
+		}
+		client := euroxref.New(4, 0, opts...)
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		res, err := client.Fetch(test.Date)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(test.Expected, res) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.Expected, res, i)
+		}
+	}
+}
+
+func TestSmartConvert(t *testing.T) {
+	// SmartConvert resolves present day in UTC as the client has no Location set.
+	today := time.Now().UTC()
+	day := func(daysAgo int, rate string) euroxref.XRefRawData {
+		return euroxref.XRefRawData{
+			RateTime: today.AddDate(0, 0, -daysAgo).Format(euroxref.XRefDateLayout),
+			Rates:    []euroxref.RawExchangeRate{{Currency: "USD", Rate: rate}},
+		}
+	}
+	routes := map[string]*euroxref.XRefRawResponse{
+		"/stats/eurofxref/eurofxref-daily.xml": {
+			Data: []euroxref.XRefRawData{day(0, "1.1")},
+		},
+		"/stats/eurofxref/eurofxref-hist-90d.xml": {
+			Data: []euroxref.XRefRawData{day(10, "1.2"), day(30, "1.3")},
+		},
+		"/stats/eurofxref/eurofxref-hist.xml": {
+			Data: []euroxref.XRefRawData{day(10, "2.2"), day(30, "1.4"), day(200, "1.5")},
+		},
+	}
+	tests := []struct {
+		DaysAgo  int
+		Expected float64
+		Err      bool
+	}{
+		{
+			DaysAgo:  0,
+			Expected: 11,
+			Err:      false,
+		},
+		{
+			DaysAgo:  10,
+			Expected: 12,
+			Err:      false,
+		},
+		{
+			DaysAgo:  30,
+			Expected: 13,
+			Err:      false,
+		},
+		{
+			DaysAgo:  200,
+			Expected: 15,
+			Err:      false,
+		},
+		{
+			DaysAgo: 95,
+			Err:     true,
+		},
+	}
+	requests := make(map[string]int)
+	handler := routeHandle(routes)
+	client := euroxref.New(4, 60)
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		requests[req.URL.Path]++
+		handler(w, req)
+	})
+	defer mock.Close()
+	for i, test := range tests {
+		res, err := client.SmartConvert(10, "EUR", "USD", today.AddDate(0, 0, -test.DaysAgo))
+		if test.Err {
+			var dErr *euroxref.ErrDateNotFound
+			if !errors.As(err, &dErr) {
+				t.Errorf("Want *ErrDateNotFound; got %v (i:%d)", err, i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if test.Expected != res {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.Expected, res, i)
+		}
+	}
+	for path, count := range requests {
+		if count != 1 {
+			t.Errorf("Want %s to be fetched once; got %d", path, count)
+		}
+	}
+	if len(requests) != 3 {
+		t.Errorf("Want all 3 feeds to be fetched; got %v", requests)
+	}
+	// Client retrieves 90 day feed by default, so its data is used instead of a separate copy.
+	if !client.(*euroxref.Client).Ready() {
+		t.Errorf("Want 90 day feed to be held by the client itself")
+	}
+}
+
+func TestHistoryMode(t *testing.T) {
+	tests := []struct {
+		Mode       euroxref.HistoryMode
+		Historical bool
+		Date       time.Time
+		Expected   euroxref.ExchangeRates
+		Err        bool
+	}{
+		{
+			Mode: euroxref.Last90Days,
+			Date: time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Expected: euroxref.ExchangeRates{
+				{Currency: "USD", Rate: 1.002},
+				{Currency: "CHF", Rate: 1.03},
+				{Currency: "PLN", Rate: 0.321},
+				{Currency: "XYZ", Rate: 1.9999999},
+			},
+			Err: false,
+		},
+		{
+			Mode: euroxref.Last90Days,
+			Date: time.Date(2016, time.January, 4, 0, 0, 0, 0, time.UTC),
+			Err:  true,
+		},
+		{
+			Mode:     euroxref.FullHistory,
+			Date:     time.Date(2016, time.January, 4, 0, 0, 0, 0, time.UTC),
+			Expected: euroxref.ExchangeRates{{Currency: "USD", Rate: 1.09}},
+			Err:      false,
+		},
+		{
+			Mode: euroxref.FullHistory,
+			Date: time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Expected: euroxref.ExchangeRates{
+				{Currency: "USD", Rate: 1.1},
+				{Currency: "CHF", Rate: 1.03},
+			},
+			Err: false,
+		},
+		{
+			Mode:       euroxref.Last90Days,
+			Historical: true,
+			Date:       time.Date(2016, time.January, 4, 0, 0, 0, 0, time.UTC),
+			Expected:   euroxref.ExchangeRates{{Currency: "USD", Rate: 1.09}},
+			Err:        false,
+		},
+		{
+			Mode:       euroxref.FullHistory,
+			Historical: true,
+			Date:       time.Date(2016, time.January, 4, 0, 0, 0, 0, time.UTC),
+			Expected:   euroxref.ExchangeRates{{Currency: "USD", Rate: 1.09}},
+			Err:        false,
+		},
+	}
+	handler := routeHandle(map[string]*euroxref.XRefRawResponse{
+		"/stats/eurofxref/eurofxref-hist-90d.xml": testResponse,
+		"/stats/eurofxref/eurofxref-hist.xml":     revisedResponse,
+	})
+	for i, test := range tests {
+		client := euroxref.New(4, 0, euroxref.WithHistoryMode(test.Mode))
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		var res euroxref.ExchangeRates
+		var err error
+		if test.Historical {
+			res, err = client.FetchHistorical(test.Date)
+		} else {
+			res, err = client.Fetch(test.Date)
+		}
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(test.Expected, res) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.Expected, res, i)
+		}
+	}
+}
+
+func TestFetchLatest(t *testing.T) {
+	daily := `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time="2016-11-14">
+			<Cube currency="USD" rate="1.0765"/>
+			<Cube currency="JPY" rate="115.89"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+	tests := []struct {
+		Body     string
+		Date     time.Time
+		Expected euroxref.ExchangeRates
+		Err      bool
+	}{
+		{
+			Body: daily,
+			Date: time.Date(2016, time.November, 14, 0, 0, 0, 0, time.UTC),
+			Expected: euroxref.ExchangeRates{
+				{Currency: "USD", Rate: 1.0765},
+				{Currency: "JPY", Rate: 115.89},
+			},
+			Err: false,
+		},
+		{
+			Body: `<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01"><Cube></Cube></gesmes:Envelope>`,
+			Err:  true,
+		},
+	}
+	la, _ := time.LoadLocation("America/Los_Angeles")
+	for i, test := range tests {
+		var path string
+		client := euroxref.New(4, 0, euroxref.WithLocation(la))
+		mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+			path = req.URL.Path
+			w.Write([]byte(test.Body))
+		})
+		rates, date, err := client.FetchLatest()
+		mock.Close()
+		if path != "/stats/eurofxref/eurofxref-daily.xml" {
+			t.Errorf("Want daily feed to be fetched; got %s (i:%d)", path, i)
+		}
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !date.Equal(test.Date) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", date, test.Date, i)
+		}
+		if !reflect.DeepEqual(rates, test.Expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", rates, test.Expected, i)
+		}
+	}
+}
+
+func TestDailyRates(t *testing.T) {
+	daily := &euroxref.XRefRawResponse{
+		Data: []euroxref.XRefRawData{
+			{
+				RateTime: "2016-11-12",
+				Rates: []euroxref.RawExchangeRate{
+					{
+						Currency: "USD",
+						Rate:     "1.2",
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		Daily    bool
+		Routes   map[string]*euroxref.XRefRawResponse
+		Date     time.Time
+		Expected euroxref.ExchangeRates
+		Days     int
+		Err      bool
+	}{
+		{
+			Daily: false,
+			Routes: map[string]*euroxref.XRefRawResponse{
+				"/stats/eurofxref/eurofxref-hist-90d.xml": testResponse,
+				"/stats/eurofxref/eurofxref-daily.xml":    daily,
+			},
+			Date: time.Date(2016, time.November, 12, 0, 0, 0, 0, time.UTC),
+			Err:  true,
+		},
+		{
+			Daily: true,
+			Routes: map[string]*euroxref.XRefRawResponse{
+				"/stats/eurofxref/eurofxref-hist-90d.xml": testResponse,
+				"/stats/eurofxref/eurofxref-daily.xml":    daily,
+			},
+			Date: time.Date(2016, time.November, 12, 0, 0, 0, 0, time.UTC),
+			Expected: euroxref.ExchangeRates{
+				{Currency: "USD", Rate: 1.2},
+			},
+			Days: 4,
+			Err:  false,
+		},
+		{
+			Daily: true,
+			Routes: map[string]*euroxref.XRefRawResponse{
+				"/stats/eurofxref/eurofxref-hist-90d.xml": testResponse,
+				"/stats/eurofxref/eurofxref-daily.xml":    daily,
+			},
+			Date: time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Expected: euroxref.ExchangeRates{
+				{Currency: "USD", Rate: 1.002},
+				{Currency: "CHF", Rate: 1.03},
+				{Currency: "PLN", Rate: 0.321},
+				{Currency: "XYZ", Rate: 1.9999999},
+			},
+			Days: 4,
+			Err:  false,
+		},
+		{
+			// Daily feed takes precedence for date present in both.
+			Daily: true,
+			Routes: map[string]*euroxref.XRefRawResponse{
+				"/stats/eurofxref/eurofxref-hist-90d.xml": testResponse,
+				"/stats/eurofxref/eurofxref-daily.xml":    {Data: revisedResponse.Data[:1]},
+			},
+			Date: time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Expected: euroxref.ExchangeRates{
+				{Currency: "USD", Rate: 1.1},
+				{Currency: "CHF", Rate: 1.03},
+			},
+			Days: 3,
+			Err:  false,
+		},
+		{
+			Daily: true,
+			Routes: map[string]*euroxref.XRefRawResponse{
+				"/stats/eurofxref/eurofxref-hist-90d.xml": testResponse,
+			},
+			Date: time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Err:  true,
+		},
+	}
+	for i, test := range tests {
+		var opts []euroxref.Option
+		if test.Daily {
+			opts = append(opts, euroxref.WithDailyRates())
+		}
+		client := euroxref.New(4, 60, opts...)
+		mock := MockServer(t, client.(*euroxref.Client), routeHandle(test.Routes))
+		res, err := client.Fetch(test.Date)
+		if test.Err {
+			mock.Close()
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(test.Expected, res) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.Expected, res, i)
+		}
+		all, err := client.FetchAll()
+		mock.Close()
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if len(all) != test.Days {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", len(all), test.Days, i)
+		}
+	}
+}
+
+func TestRevisedRatesUnavailable(t *testing.T) {
+	requests := make(map[string]int)
+	client := euroxref.New(4, 60, euroxref.WithRevisedRates())
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		requests[req.URL.Path]++
+		if req.URL.Path == "/stats/eurofxref/eurofxref-hist.xml" {
+			http.NotFound(w, req)
+			return
+		}
+		xmlHandle(testResponse)(w, req)
+	})
+	defer mock.Close()
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	// Unrevised data is never stored, so every call attempts to retrieve both feeds again.
+	for i := 0; i < 2; i++ {
+		if _, err := client.Fetch(date); err == nil {
+			t.Errorf("Want err != nil; got nil (i:%d)", i)
+		}
+	}
+	if requests["/stats/eurofxref/eurofxref-hist-90d.xml"] != 2 || requests["/stats/eurofxref/eurofxref-hist.xml"] != 2 {
+		t.Errorf("Want both feeds requested twice; got %v", requests)
+	}
+	if client.Ready() {
+		t.Errorf("Want client not to be ready")
+	}
+}
+
+func TestRevisedRatesFullHistory(t *testing.T) {
+	requests := make(map[string]int)
+	client := euroxref.New(4, 0, euroxref.WithHistoryMode(euroxref.FullHistory), euroxref.WithRevisedRates())
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		requests[req.URL.Path]++
+		xmlHandle(revisedResponse)(w, req)
+	})
+	defer mock.Close()
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	// Full history already holds revised rates, so it's retrieved once per refresh.
+	for i := 0; i < 2; i++ {
+		rates, err := client.Fetch(date)
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if rate := rates.Map()["USD"]; rate != 1.1 {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", 1.1, rate, i)
+		}
+	}
+	expected := map[string]int{"/stats/eurofxref/eurofxref-hist.xml": 2}
+	if !reflect.DeepEqual(expected, requests) {
+		t.Errorf("Values `%v` and `%v` are not equal", expected, requests)
+	}
+}
+
+func TestDailyRatesUnavailable(t *testing.T) {
+	requests := make(map[string]int)
+	client := euroxref.New(4, 60, euroxref.WithDailyRates())
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		requests[req.URL.Path]++
+		if req.URL.Path == "/stats/eurofxref/eurofxref-daily.xml" {
+			http.NotFound(w, req)
+			return
+		}
+		xmlHandle(testResponse)(w, req)
+	})
+	defer mock.Close()
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	// Data without the daily feed is never stored, so every call attempts to retrieve both feeds again.
+	for i := 0; i < 2; i++ {
+		if _, err := client.Fetch(date); err == nil {
+			t.Errorf("Want err != nil; got nil (i:%d)", i)
+		}
+	}
+	if requests["/stats/eurofxref/eurofxref-hist-90d.xml"] != 2 || requests["/stats/eurofxref/eurofxref-daily.xml"] != 2 {
+		t.Errorf("Want both feeds requested twice; got %v", requests)
+	}
+	if client.Ready() {
+		t.Errorf("Want client not to be ready")
+	}
+}
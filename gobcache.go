@@ -0,0 +1,117 @@
+package euroxref
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// gobCacheVersion identifies layout of the gob cache file, it has to be bumped
+// whenever gobCache or ExchangeRate structs change so that stale files are ignored.
+const gobCacheVersion = 3
+
+// gobCache represents parsed exchange rate data persisted between process restarts.
+type gobCache struct {
+	Version      int
+	Source       string
+	Precision    int
+	RoundedRates bool
+	RoundingMode RoundingMode
+	FetchedAt    time.Time
+	Days         []gobCacheDay
+}
+
+// gobCacheDay represents parsed exchange rates for a single day.
+type gobCacheDay struct {
+	Date  string
+	Rates ExchangeRates
+}
+
+// WithGobCache makes client persist parsed exchange rates in gob file under path.
+// Cached data is loaded on first use, which skips XML decoding on cold start, and rewritten
+// after every refresh. Files written with different format version, source of the data (SourceURL and feeds
+// merged into it), precision, rate rounding or rounding mode are ignored.
+func WithGobCache(path string) Option {
+	return func(c *Client) {
+		c.gobCachePath = path
+	}
+}
+
+// loadGobCache populates client data from gob cache file.
+func (c *Client) loadGobCache() (err error) {
+	f, err := os.Open(c.gobCachePath)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	cache := &gobCache{}
+	err = gob.NewDecoder(f).Decode(cache)
+	if err != nil || !c.matchesGobCache(cache) {
+		return
+	}
+	data := &XRefRawResponse{}
+	parsed := make(map[string]ExchangeRates)
+	for _, day := range cache.Days {
+		raw := XRefRawData{RateTime: day.Date}
+		for _, rec := range day.Rates {
+			raw.Rates = append(raw.Rates, RawExchangeRate{
+				Currency: rec.Currency,
+				Rate:     strconv.FormatFloat(rec.Rate, 'f', -1, 64),
+			})
+		}
+		data.Data = append(data.Data, raw)
+		parsed[day.Date] = day.Rates
+	}
+	c.XRefData = data
+	c.parsed = parsed
+	c.lastFetched = cache.FetchedAt
+	return
+}
+
+// matchesGobCache checks if cache was written by client retrieving and parsing data same as c.
+func (c *Client) matchesGobCache(cache *gobCache) bool {
+	return cache.Version == gobCacheVersion &&
+		cache.Source == c.cacheKey() &&
+		cache.Precision == c.prec &&
+		cache.RoundedRates == c.roundRates &&
+		cache.RoundingMode == c.roundingMode
+}
+
+// writeGobCache persists currently parsed data into gob cache file.
+// File is replaced atomically so concurrent readers never see partial data.
+func (c *Client) writeGobCache() (err error) {
+	cache := &gobCache{
+		Version:      gobCacheVersion,
+		Source:       c.cacheKey(),
+		Precision:    c.prec,
+		RoundedRates: c.roundRates,
+		RoundingMode: c.roundingMode,
+		FetchedAt:    c.lastFetched,
+	}
+	var rates ExchangeRates
+	for _, dayD := range c.XRefData.Data {
+		if len(dayD.Rates) == 0 {
+			continue
+		}
+		rates, err = c.parseDay(dayD.RateTime)
+		if err != nil {
+			return
+		}
+		cache.Days = append(cache.Days, gobCacheDay{Date: dayD.RateTime, Rates: rates})
+	}
+	f, err := os.CreateTemp(filepath.Dir(c.gobCachePath), filepath.Base(c.gobCachePath)+".tmp")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+	err = gob.NewEncoder(f).Encode(cache)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return
+	}
+	return os.Rename(f.Name(), c.gobCachePath)
+}
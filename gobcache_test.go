@@ -0,0 +1,114 @@
+package euroxref_test
+
+import (
+	"github.com/exaroth/euroxref-konrad"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGobCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.gob")
+	date := time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC)
+
+	var reqUrl, reqMethod, reqBody string
+	handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+	client := euroxref.New(4, 60, euroxref.WithGobCache(path))
+	mock := MockServer(t, client.(*euroxref.Client), handler)
+	expected, err := client.Fetch(date)
+	mock.Close()
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+
+	// Second client should be served from the gob file without hitting the server.
+	requests := 0
+	client = euroxref.New(4, 60, euroxref.WithGobCache(path))
+	mock = MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	defer mock.Close()
+	res, err := client.Fetch(date)
+	if err != nil {
+		t.Errorf("Want err == nil; got %v", err)
+	}
+	if !reflect.DeepEqual(expected, res) {
+		t.Errorf("Values `%v` and `%v` are not equal", expected, res)
+	}
+	if _, err := client.Fetch(time.Date(2016, time.November, 8, 23, 0, 0, 0, time.UTC)); err == nil {
+		t.Errorf("Want err != nil for date without data; got nil")
+	}
+	if requests != 0 {
+		t.Errorf("Want 0 requests; got %d", requests)
+	}
+
+	// Cache written with different precision has to be ignored.
+	client = euroxref.New(6, 60, euroxref.WithGobCache(path))
+	mock = MockServer(t, client.(*euroxref.Client), handler)
+	defer mock.Close()
+	res, err = client.Fetch(date)
+	if err != nil {
+		t.Errorf("Want err == nil; got %v", err)
+	}
+	if res[0].Rate != 1.003123142 {
+		t.Errorf("Values `%v` and `%v` are not equal", 1.003123142, res[0].Rate)
+	}
+}
+
+func TestGobCacheMismatch(t *testing.T) {
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	handler := routeHandle(map[string]*euroxref.XRefRawResponse{
+		"/stats/eurofxref/eurofxref-hist-90d.xml": testResponse,
+		"/stats/eurofxref/eurofxref-hist.xml":     revisedResponse,
+	})
+	tests := []struct {
+		Options  []euroxref.Option
+		Requests int
+	}{
+		{
+			Options:  nil,
+			Requests: 0,
+		},
+		{
+			Options:  []euroxref.Option{euroxref.WithRevisedRates()},
+			Requests: 2,
+		},
+		{
+			Options:  []euroxref.Option{euroxref.WithHistoryMode(euroxref.FullHistory)},
+			Requests: 1,
+		},
+		{
+			Options:  []euroxref.Option{euroxref.WithRoundingMode(euroxref.HalfEven)},
+			Requests: 1,
+		},
+	}
+	for i, test := range tests {
+		path := filepath.Join(t.TempDir(), "rates.gob")
+		client := euroxref.New(4, 60, euroxref.WithGobCache(path))
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		_, err := client.Fetch(date)
+		mock.Close()
+		if err != nil {
+			t.Fatalf("Want err == nil; got %v (i:%d)", err, i)
+		}
+
+		// Cache written by client retrieving or rounding data differently has to be ignored.
+		requests := 0
+		client = euroxref.New(4, 60, append(test.Options, euroxref.WithGobCache(path))...)
+		mock = MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+			requests++
+			handler(w, req)
+		})
+		_, err = client.Fetch(date)
+		mock.Close()
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if requests != test.Requests {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", requests, test.Requests, i)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package euroxref
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// gzipMagic holds leading bytes of every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// decodedBody returns reader with contents of the response body decompressed
+// if server declared it as gzip encoded either via Content-Encoding header or .gz suffix of the url.
+// Body which is declared as compressed but doesn't start with gzip header is returned as is.
+func decodedBody(resp *http.Response, url string) (io.Reader, error) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding != "gzip" && encoding != "x-gzip" && !strings.HasSuffix(strings.ToLower(url), ".gz") {
+		return resp.Body, nil
+	}
+	body := bufio.NewReader(resp.Body)
+	magic, _ := body.Peek(len(gzipMagic))
+	if len(magic) < len(gzipMagic) || magic[0] != gzipMagic[0] || magic[1] != gzipMagic[1] {
+		return body, nil
+	}
+	return gzip.NewReader(body)
+}
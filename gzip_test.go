@@ -0,0 +1,72 @@
+package euroxref_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"github.com/exaroth/euroxref-konrad"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGzipResponse(t *testing.T) {
+	data, err := xml.Marshal(testResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write(data)
+	zw.Close()
+
+	tests := []struct {
+		SourceURL string
+		Encoding  string
+		Body      []byte
+	}{
+		{
+			SourceURL: "https://example.com/eurofxref-hist.xml",
+			Encoding:  "",
+			Body:      data,
+		},
+		{
+			SourceURL: "https://example.com/eurofxref-hist.xml",
+			Encoding:  "gzip",
+			Body:      compressed.Bytes(),
+		},
+		{
+			SourceURL: "https://example.com/eurofxref-hist.xml.gz",
+			Encoding:  "",
+			Body:      compressed.Bytes(),
+		},
+		{
+			SourceURL: "https://example.com/eurofxref-hist.xml",
+			Encoding:  "gzip",
+			Body:      data,
+		},
+		{
+			SourceURL: "https://example.com/eurofxref-hist.xml.gz",
+			Encoding:  "",
+			Body:      data,
+		},
+	}
+	for i, test := range tests {
+		client := euroxref.New(4, 0, euroxref.WithSourceURL(test.SourceURL))
+		mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+			if test.Encoding != "" {
+				w.Header().Set("Content-Encoding", test.Encoding)
+			}
+			w.Write(test.Body)
+		})
+		res, err := client.Fetch(time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC))
+		mock.Close()
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			continue
+		}
+		if len(res) != 4 {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", len(res), 4, i)
+		}
+	}
+}
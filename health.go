@@ -0,0 +1,66 @@
+package euroxref
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Healthcheck verifies that client can reach SourceURL and, if MaxDataAge is set, that data it holds
+// was retrieved recently enough, e.g. for wiring readiness probes directly to the client.
+// Source is checked with a HEAD request, falling back to GET for servers which don't support it,
+// without downloading or decoding the data. Request is aborted when ctx is done.
+func (c *Client) Healthcheck(ctx context.Context) (err error) {
+	c.mu.RLock()
+	closed := c.closed
+	c.mu.RUnlock()
+	if closed {
+		return ErrClientClosed
+	}
+	if c.SourceURL == "" {
+		return errors.New("Source url for exchange rate data is not set")
+	}
+	status, err := c.probeSource(ctx, http.MethodHead)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = c.probeSource(ctx, http.MethodGet)
+	}
+	if err != nil {
+		return errors.New(fmt.Sprintf("Source %s is unreachable: %v", c.SourceURL, err))
+	}
+	if status != http.StatusOK {
+		return &StatusError{
+			URL:        c.SourceURL,
+			StatusCode: status,
+			Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		}
+	}
+	if c.MaxDataAge <= 0 {
+		return nil
+	}
+	if c.LastFetched().IsZero() {
+		return errors.New("No exchange rate data retrieved yet")
+	}
+	if age := c.DataAge(); age > c.MaxDataAge {
+		return errors.New(fmt.Sprintf("Exchange rate data is stale: retrieved %s ago, accepted age is %s", age, c.MaxDataAge))
+	}
+	return nil
+}
+
+// probeSource sends request with given method to SourceURL and returns status code of the response.
+// Body of the response is never read.
+func (c *Client) probeSource(ctx context.Context, method string) (status int, err error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.SourceURL, nil)
+	if err != nil {
+		return
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,104 @@
+package euroxref_test
+
+import (
+	"context"
+	"errors"
+	"github.com/exaroth/euroxref-konrad"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHealthcheck(t *testing.T) {
+	tests := []struct {
+		HeadStatus int
+		MaxDataAge time.Duration
+		Fetch      bool
+		Close      bool
+		Methods    []string
+		StatusErr  bool
+		Err        bool
+	}{
+		{
+			HeadStatus: http.StatusOK,
+			Methods:    []string{http.MethodHead},
+			Err:        false,
+		},
+		{
+			HeadStatus: http.StatusServiceUnavailable,
+			Methods:    []string{http.MethodHead},
+			StatusErr:  true,
+			Err:        true,
+		},
+		{
+			HeadStatus: http.StatusMethodNotAllowed,
+			Methods:    []string{http.MethodHead, http.MethodGet},
+			Err:        false,
+		},
+		{
+			HeadStatus: http.StatusOK,
+			MaxDataAge: time.Hour,
+			Methods:    []string{http.MethodHead},
+			Err:        true,
+		},
+		{
+			HeadStatus: http.StatusOK,
+			MaxDataAge: time.Hour,
+			Fetch:      true,
+			Methods:    []string{http.MethodGet, http.MethodHead},
+			Err:        false,
+		},
+		{
+			HeadStatus: http.StatusOK,
+			MaxDataAge: time.Nanosecond,
+			Fetch:      true,
+			Methods:    []string{http.MethodGet, http.MethodHead},
+			Err:        true,
+		},
+		{
+			Close: true,
+			Err:   true,
+		},
+	}
+	for i, test := range tests {
+		var methods []string
+		client := euroxref.New(4, 60)
+		client.(*euroxref.Client).MaxDataAge = test.MaxDataAge
+		mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+			methods = append(methods, req.Method)
+			if req.Method == http.MethodHead {
+				w.WriteHeader(test.HeadStatus)
+				return
+			}
+			xmlHandle(testResponse)(w, req)
+		})
+		if test.Fetch {
+			if _, err := client.Fetch(time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)); err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if test.Close {
+			client.Close()
+		}
+		err := client.Healthcheck(context.Background())
+		mock.Close()
+		if test.Close && !errors.Is(err, euroxref.ErrClientClosed) {
+			t.Errorf("Want ErrClientClosed; got %v (i:%d)", err, i)
+		}
+		var sErr *euroxref.StatusError
+		if test.StatusErr && !errors.As(err, &sErr) {
+			t.Errorf("Want *StatusError; got %v (i:%d)", err, i)
+		}
+		if test.Err && err == nil {
+			t.Errorf("Want err != nil; got nil (i:%d)", i)
+		}
+		if !test.Err && err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(methods, test.Methods) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", methods, test.Methods, i)
+		}
+	}
+}
@@ -0,0 +1,458 @@
+package euroxref
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PrewarmRecent parses the newest n available days into the per-date parse cache,
+// so subsequent calls to Fetch and Convert for those days don't have to parse the data again.
+func (c *Client) PrewarmRecent(n int) (err error) {
+	if n < 0 {
+		return errors.New(fmt.Sprintf("Invalid number of days to prewarm: %d", n))
+	}
+	err = c.fetchXML(context.Background())
+	if err != nil {
+		return
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var keys []string
+	for _, dayD := range c.XRefData.Data {
+		if len(dayD.Rates) > 0 {
+			keys = append(keys, dayD.RateTime)
+		}
+	}
+	// Dates are in XRefDateLayout so lexical order matches chronological one.
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	if n < len(keys) {
+		keys = keys[:n]
+	}
+	for _, key := range keys {
+		if _, err = c.parseDay(key); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// CurrencyCoverage returns the oldest and newest dates for which given currency
+// has exchange rate data available along with the number of days it has been published.
+func (c *Client) CurrencyCoverage(currency string) (oldest, newest time.Time, dayCount int, err error) {
+	currency = strings.ToUpper(currency)
+	err = c.fetchXML(context.Background())
+	if err != nil {
+		return
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var t time.Time
+	for _, dayD := range c.XRefData.Data {
+		if !hasCurrency(dayD.Rates, currency) {
+			continue
+		}
+		t, err = time.Parse(XRefDateLayout, dayD.RateTime)
+		if err != nil {
+			return
+		}
+		if dayCount == 0 || t.Before(oldest) {
+			oldest = t
+		}
+		if dayCount == 0 || t.After(newest) {
+			newest = t
+		}
+		dayCount++
+	}
+	if dayCount == 0 {
+		return oldest, newest, dayCount, errors.New(fmt.Sprintf("No exchange rate data available for %s", currency))
+	}
+	return
+}
+
+// DateRange returns dates of the earliest and latest exchange rates available,
+// which bound dates that can be passed to range queries such as ConvertRange.
+func (c *Client) DateRange() (earliest, latest time.Time, err error) {
+	err = c.fetchXML(context.Background())
+	if err != nil {
+		return
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	first, last := "", ""
+	for _, dayD := range c.XRefData.Data {
+		if len(dayD.Rates) == 0 {
+			continue
+		}
+		// Dates are in XRefDateLayout so lexical order matches chronological one.
+		if first == "" || dayD.RateTime < first {
+			first = dayD.RateTime
+		}
+		if dayD.RateTime > last {
+			last = dayD.RateTime
+		}
+	}
+	if first == "" {
+		return earliest, latest, errors.New("No exchange rate data available")
+	}
+	earliest, err = time.Parse(XRefDateLayout, first)
+	if err != nil {
+		return
+	}
+	latest, err = time.Parse(XRefDateLayout, last)
+	return
+}
+
+// PublishedDates returns sorted dates between from and to (inclusive) for which ECB published exchange rates,
+// which excludes weekends as well as TARGET closing days such as New Year or Good Friday.
+func (c *Client) PublishedDates(from, to time.Time) (dates []time.Time, err error) {
+	fromKey, toKey := c.dateKey(from), c.dateKey(to)
+	if fromKey > toKey {
+		return dates, errors.New(fmt.Sprintf("Invalid date range: %s is after %s", fromKey, toKey))
+	}
+	err = c.fetchXML(context.Background())
+	if err != nil {
+		return
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	seen := make(map[string]bool)
+	var t time.Time
+	for _, dayD := range c.XRefData.Data {
+		if dayD.RateTime < fromKey || dayD.RateTime > toKey || len(dayD.Rates) == 0 || seen[dayD.RateTime] {
+			continue
+		}
+		seen[dayD.RateTime] = true
+		t, err = time.Parse(XRefDateLayout, dayD.RateTime)
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, t)
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+	return
+}
+
+// hasCurrency checks if currency is present in the list of raw rates,
+// EUR is considered present on every day that has any rates published.
+func hasCurrency(rates []RawExchangeRate, currency string) bool {
+	if len(rates) > 0 && currency == EUCurr {
+		return true
+	}
+	for _, rec := range rates {
+		if rec.Currency == currency {
+			return true
+		}
+	}
+	return false
+}
+
+// PairChange returns cross rate between currencies a and b on both from and to dates
+// along with percentage change between them.
+// If either date has no data *ErrDateNotFound for that date is returned.
+func (c *Client) PairChange(a, b string, from, to time.Time) (fromRate, toRate, pct float64, err error) {
+	fromRate, err = c.crossRate(a, b, from)
+	if err != nil {
+		return
+	}
+	toRate, err = c.crossRate(a, b, to)
+	if err != nil {
+		return
+	}
+	if fromRate == 0 {
+		return fromRate, toRate, pct, errors.New(fmt.Sprintf("Cross rate between %s and %s on %s is zero", a, b, from.Format(XRefDateLayout)))
+	}
+	return fromRate, toRate, c.round((toRate - fromRate) / fromRate * 100), nil
+}
+
+// PercentChange returns signed percentage change of currency exchange rate against EUR between from and to dates,
+// rounded to client precision. Positive change means the currency weakened against EUR.
+func (c *Client) PercentChange(currency string, from, to time.Time) (pct float64, err error) {
+	_, _, pct, err = c.PairChange(EUCurr, currency, from, to)
+	return
+}
+
+// crossRate returns the rate of target currency per unit of source currency on given date.
+func (c *Client) crossRate(source, target string, t time.Time) (rate float64, err error) {
+	dayData, err := c.Fetch(t)
+	if err != nil {
+		return
+	}
+	in, to, err := lookupPair(dayData, source, target, t)
+	if err != nil {
+		return
+	}
+	return c.pairRate(in, to)
+}
+
+// ConvertRange converts amount same as Convert for every day between from and to (inclusive)
+// returning map of dates to converted amounts. Days without published rates for either
+// of the currencies are absent from the result. Data is retrieved only once for the whole range.
+func (c *Client) ConvertRange(amount float64, source, target string, from, to time.Time) (results map[time.Time]float64, err error) {
+	fromKey, toKey := c.dateKey(from), c.dateKey(to)
+	if fromKey > toKey {
+		return results, errors.New(fmt.Sprintf("Invalid date range: %s is after %s", fromKey, toKey))
+	}
+	codes, err := c.checkCurrencies(source, target)
+	if err != nil {
+		return
+	}
+	source, target = codes[0], codes[1]
+	err = c.fetchXML(context.Background())
+	if err != nil {
+		return
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	results = make(map[time.Time]float64)
+	var rates ExchangeRates
+	var in, out *ExchangeRate
+	var t time.Time
+	for _, dayD := range c.XRefData.Data {
+		if dayD.RateTime < fromKey || dayD.RateTime > toKey || len(dayD.Rates) == 0 {
+			continue
+		}
+		t, err = time.Parse(XRefDateLayout, dayD.RateTime)
+		if err != nil {
+			return nil, err
+		}
+		rates, err = c.parseDay(dayD.RateTime)
+		if err != nil {
+			return nil, err
+		}
+		in, out, err = lookupPair(rates, source, target, t)
+		if _, missing := err.(*ErrCurrencyNotFound); missing {
+			err = nil
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		results[t], err = c.computeExchangeValue(amount, in, out, c.resultParams(target)...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+// CrossRateMatrix returns rates between every ordered pair of currencies (including EUR) available on given date,
+// matrix[a][b] being the rate of currency b per unit of currency a rounded to client precision.
+// Data for the date is fetched only once.
+func (c *Client) CrossRateMatrix(t time.Time) (matrix map[string]map[string]float64, err error) {
+	dayData, err := c.Fetch(t)
+	if err != nil {
+		return
+	}
+	rates := withEUR(dayData)
+	matrix = make(map[string]map[string]float64, len(rates))
+	var rebased ExchangeRates
+	for idx := range rates {
+		rebased, err = c.rebase(rates, &rates[idx])
+		if err != nil {
+			return nil, err
+		}
+		matrix[rates[idx].Currency] = rebased.Map()
+	}
+	return
+}
+
+// FetchBase retrieves exchange rates for given day same as Fetch, expressed per unit of base currency
+// instead of EUR. EUR is included in the result, as is base currency with rate of 1.
+func (c *Client) FetchBase(t time.Time, base string) (rates ExchangeRates, err error) {
+	dayData, err := c.Fetch(t)
+	if err != nil {
+		return
+	}
+	in, _, err := lookupPair(dayData, base, base, t)
+	if err != nil {
+		return
+	}
+	return c.rebase(withEUR(dayData), in)
+}
+
+// RateTable retrieves exchange rates for given day same as Fetch, ordered for display regardless of
+// their order in the feed: EUR with rate of 1 first, followed by other currencies in alphabetical order.
+func (c *Client) RateTable(t time.Time) (rates ExchangeRates, err error) {
+	dayData, err := c.Fetch(t)
+	if err != nil {
+		return
+	}
+	rates = withEUR(dayData)
+	rest := rates[1:]
+	sort.Slice(rest, func(i, j int) bool {
+		return rest[i].Currency < rest[j].Currency
+	})
+	return
+}
+
+// withEUR returns EUR relative rates including EUR itself.
+func withEUR(rates ExchangeRates) ExchangeRates {
+	return append(ExchangeRates{{Currency: EUCurr, Rate: EURate}}, rates...)
+}
+
+// rebase returns rates expressed per unit of base currency rounded to client precision.
+func (c *Client) rebase(rates ExchangeRates, base *ExchangeRate) (rebased ExchangeRates, err error) {
+	rebased = make(ExchangeRates, len(rates))
+	for idx := range rates {
+		rebased[idx].Currency = rates[idx].Currency
+		rebased[idx].Rate, err = c.pairRate(base, &rates[idx])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+// RateSlices returns dates and EUR relative rates of currency between from and to (inclusive)
+// as index-aligned slices sorted by date. Days on which currency wasn't published are skipped.
+func (c *Client) RateSlices(currency string, from, to time.Time) (dates []time.Time, values []float64, err error) {
+	currency = strings.ToUpper(currency)
+	fromKey, toKey := c.dateKey(from), c.dateKey(to)
+	if fromKey > toKey {
+		return dates, values, errors.New(fmt.Sprintf("Invalid date range: %s is after %s", fromKey, toKey))
+	}
+	err = c.fetchXML(context.Background())
+	if err != nil {
+		return
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var keys []string
+	for _, dayD := range c.XRefData.Data {
+		if dayD.RateTime >= fromKey && dayD.RateTime <= toKey && hasCurrency(dayD.Rates, currency) {
+			keys = append(keys, dayD.RateTime)
+		}
+	}
+	sort.Strings(keys)
+	var rates ExchangeRates
+	var t time.Time
+	for _, key := range keys {
+		rates, err = c.parseDay(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		t, err = time.Parse(XRefDateLayout, key)
+		if err != nil {
+			return nil, nil, err
+		}
+		rate := EURate
+		for _, rec := range rates {
+			if rec.Currency == currency {
+				rate = rec.Rate
+				break
+			}
+		}
+		dates = append(dates, t)
+		values = append(values, rate)
+	}
+	return
+}
+
+// FetchAllAligned retrieves all available records same as FetchAll, with every day holding the same set
+// of currencies, so that they can be laid out as a table. currencies is the sorted union of currencies
+// published on any day, rates of currencies which weren't published on a given day are NaN.
+func (c *Client) FetchAllAligned() (rates map[time.Time]map[string]float64, currencies []string, err error) {
+	all, err := c.FetchAll()
+	if err != nil {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, dayData := range all {
+		for _, rec := range dayData {
+			if !seen[rec.Currency] {
+				seen[rec.Currency] = true
+				currencies = append(currencies, rec.Currency)
+			}
+		}
+	}
+	sort.Strings(currencies)
+	rates = make(map[time.Time]map[string]float64, len(all))
+	for t, dayData := range all {
+		day := dayData.Map()
+		for _, currency := range currencies {
+			if _, ok := day[currency]; !ok {
+				day[currency] = math.NaN()
+			}
+		}
+		rates[t] = day
+	}
+	return
+}
+
+// MovingAverage returns simple moving average of EUR relative rate of currency over window most recent days
+// on which it was published up to end (inclusive), rounded to client precision.
+// Error is returned if currency was published on fewer than window days up to end.
+func (c *Client) MovingAverage(currency string, end time.Time, window int) (avg float64, err error) {
+	if window < 1 {
+		return avg, errors.New(fmt.Sprintf("Invalid moving average window: %d", window))
+	}
+	currency = strings.ToUpper(currency)
+	endKey := c.dateKey(end)
+	err = c.fetchXML(context.Background())
+	if err != nil {
+		return
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var keys []string
+	for _, dayD := range c.XRefData.Data {
+		if dayD.RateTime <= endKey && hasCurrency(dayD.Rates, currency) {
+			keys = append(keys, dayD.RateTime)
+		}
+	}
+	// Dates are in XRefDateLayout so lexical order matches chronological one.
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	if len(keys) < window {
+		return avg, errors.New(fmt.Sprintf("Not enough data for %d day moving average of %s up to %s, %d days available", window, currency, endKey, len(keys)))
+	}
+	sum := new(big.Rat)
+	var rates ExchangeRates
+	for _, key := range keys[:window] {
+		rates, err = c.parseDay(key)
+		if err != nil {
+			return
+		}
+		in, _, lookupErr := lookupPair(rates, currency, currency, end)
+		if lookupErr != nil {
+			return avg, lookupErr
+		}
+		rate, ok := ratFromFloat(in.Rate)
+		if !ok {
+			return avg, errors.New(fmt.Sprintf("Invalid exchange rate for %s on %s", currency, key))
+		}
+		sum.Add(sum, rate)
+	}
+	return ratToFixed(sum.Quo(sum, big.NewRat(int64(window), 1)), c.prec, c.roundingMode), nil
+}
+
+// RateExtremes returns the lowest and highest EUR relative rate of currency published between from and to
+// (inclusive) along with dates on which they were published, the earliest one if the rate repeats.
+// Days on which currency wasn't published are skipped, error is returned if it wasn't published at all.
+func (c *Client) RateExtremes(currency string, from, to time.Time) (min, max float64, minDate, maxDate time.Time, err error) {
+	dates, values, err := c.RateSlices(currency, from, to)
+	if err != nil {
+		return
+	}
+	if len(values) == 0 {
+		err = errors.New(fmt.Sprintf("No exchange rate data available for %s between %s and %s", strings.ToUpper(currency), c.dateKey(from), c.dateKey(to)))
+		return
+	}
+	min, max, minDate, maxDate = values[0], values[0], dates[0], dates[0]
+	for idx, value := range values {
+		if value < min {
+			min, minDate = value, dates[idx]
+		}
+		if value > max {
+			max, maxDate = value, dates[idx]
+		}
+	}
+	return
+}
@@ -0,0 +1,944 @@
+package euroxref_test
+
+import (
+	"errors"
+	"github.com/exaroth/euroxref-konrad"
+	"math"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCurrencyCoverage(t *testing.T) {
+	tests := []struct {
+		Currency string
+		Oldest   time.Time
+		Newest   time.Time
+		DayCount int
+		Err      bool
+	}{
+		{
+			Currency: "USD",
+			Oldest:   time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+			Newest:   time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			DayCount: 3,
+			Err:      false,
+		},
+		{
+			Currency: "PLN",
+			Oldest:   time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Newest:   time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			DayCount: 2,
+			Err:      false,
+		},
+		{
+			Currency: "CHF",
+			Oldest:   time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Newest:   time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			DayCount: 1,
+			Err:      false,
+		},
+		{
+			Currency: "EUR",
+			Oldest:   time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+			Newest:   time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			DayCount: 3,
+			Err:      false,
+		},
+		{
+			Currency: "BLE",
+			Err:      true,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0)
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		oldest, newest, dayCount, err := client.CurrencyCoverage(test.Currency)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			}
+			if !test.Oldest.Equal(oldest) || !test.Newest.Equal(newest) {
+				t.Errorf("Want range %v - %v; got %v - %v (i:%d)", test.Oldest, test.Newest, oldest, newest, i)
+			}
+			if test.DayCount != dayCount {
+				t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.DayCount, dayCount, i)
+			}
+		}
+	}
+}
+
+func TestPrewarmRecent(t *testing.T) {
+	// Every day holds a malformed rate, so that each parse of a day is reported to OnMalformed.
+	response := &euroxref.XRefRawResponse{}
+	for _, day := range []string{"2016-11-09", "2016-11-11", "2016-11-10"} {
+		response.Data = append(response.Data, euroxref.XRefRawData{
+			RateTime: day,
+			Rates:    []euroxref.RawExchangeRate{{Currency: "USD", Rate: "1.002"}, {Currency: "ABC", Rate: "-"}},
+		})
+	}
+	tests := []struct {
+		Days      int
+		Prewarmed []string
+		Err       bool
+	}{
+		{
+			Days:      0,
+			Prewarmed: nil,
+			Err:       false,
+		},
+		{
+			Days:      2,
+			Prewarmed: []string{"2016-11-11", "2016-11-10"},
+			Err:       false,
+		},
+		{
+			Days:      100,
+			Prewarmed: []string{"2016-11-11", "2016-11-10", "2016-11-09"},
+			Err:       false,
+		},
+		{
+			Days: -1,
+			Err:  true,
+		},
+	}
+	date := time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC)
+	for i, test := range tests {
+		var parsed []string
+		requests := 0
+		client := euroxref.New(4, 60)
+		client.(*euroxref.Client).SkipMalformed = true
+		client.(*euroxref.Client).OnMalformed = func(err *euroxref.ParseError) {
+			parsed = append(parsed, err.RateTime)
+		}
+		mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+			requests++
+			xmlHandle(response)(w, req)
+		})
+		err := client.PrewarmRecent(test.Days)
+		if test.Err {
+			mock.Close()
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(parsed, test.Prewarmed) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", parsed, test.Prewarmed, i)
+		}
+		// Days which were prewarmed aren't parsed again.
+		parsed = nil
+		for _, day := range []time.Time{date, date.AddDate(0, 0, -1), date.AddDate(0, 0, -2)} {
+			if _, err := client.Fetch(day); err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			}
+		}
+		if expected := 3 - len(test.Prewarmed); len(parsed) != expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", len(parsed), expected, i)
+		}
+		res, err := client.Fetch(date)
+		mock.Close()
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		// Mutating returned data must not affect the cache.
+		res[0].Rate = 100
+		res, _ = client.Fetch(date)
+		if res[0].Rate != 1.002 {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", 1.002, res[0].Rate, i)
+		}
+		if requests != 1 {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", requests, 1, i)
+		}
+	}
+}
+
+func TestPairChange(t *testing.T) {
+	tests := []struct {
+		Currencies  [2]string
+		From        time.Time
+		To          time.Time
+		FromRate    float64
+		ToRate      float64
+		Pct         float64
+		MissingDate time.Time
+		Err         bool
+	}{
+		{
+			Currencies: [2]string{"USD", "PLN"},
+			From:       time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC),
+			To:         time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			FromRate:   0.3201,
+			ToRate:     0.3204,
+			Pct:        0.0937,
+			Err:        false,
+		},
+		{
+			Currencies: [2]string{"EUR", "USD"},
+			From:       time.Date(2016, time.November, 9, 23, 0, 0, 0, time.UTC),
+			To:         time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			FromRate:   3,
+			ToRate:     1.002,
+			Pct:        -66.6,
+			Err:        false,
+		},
+		{
+			Currencies:  [2]string{"USD", "PLN"},
+			From:        time.Date(2016, time.November, 8, 23, 0, 0, 0, time.UTC),
+			To:          time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			MissingDate: time.Date(2016, time.November, 8, 0, 0, 0, 0, time.UTC),
+			Err:         true,
+		},
+		{
+			Currencies:  [2]string{"USD", "PLN"},
+			From:        time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			To:          time.Date(2016, time.November, 12, 23, 0, 0, 0, time.UTC),
+			MissingDate: time.Date(2016, time.November, 12, 0, 0, 0, 0, time.UTC),
+			Err:         true,
+		},
+		{
+			Currencies: [2]string{"USD", "PLN"},
+			From:       time.Date(2016, time.November, 9, 23, 0, 0, 0, time.UTC),
+			To:         time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Err:        true,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0)
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		fromRate, toRate, pct, err := client.PairChange(test.Currencies[0], test.Currencies[1], test.From, test.To)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+				continue
+			}
+			var dErr *euroxref.ErrDateNotFound
+			if !test.MissingDate.IsZero() && (!errors.As(err, &dErr) || !dErr.Date.Equal(test.MissingDate)) {
+				t.Errorf("Want *ErrDateNotFound for %v; got %v (i:%d)", test.MissingDate, err, i)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			}
+			if test.FromRate != fromRate || test.ToRate != toRate || test.Pct != pct {
+				t.Errorf("Values `%v %v %v` and `%v %v %v` are not equal (i:%d)", test.FromRate, test.ToRate, test.Pct, fromRate, toRate, pct, i)
+			}
+		}
+	}
+}
+
+func TestRateSlices(t *testing.T) {
+	tests := []struct {
+		Currency string
+		From     time.Time
+		To       time.Time
+		Dates    []time.Time
+		Values   []float64
+		Err      bool
+	}{
+		{
+			Currency: "USD",
+			From:     time.Date(2016, time.November, 1, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.November, 30, 0, 0, 0, 0, time.UTC),
+			Dates: []time.Time{
+				time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+				time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+				time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			},
+			Values: []float64{2.999999, 1.003123142, 1.002},
+			Err:    false,
+		},
+		{
+			Currency: "PLN",
+			From:     time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC),
+			Dates: []time.Time{
+				time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			},
+			Values: []float64{0.3211231231},
+			Err:    false,
+		},
+		{
+			Currency: "EUR",
+			From:     time.Date(2016, time.November, 8, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+			Dates: []time.Time{
+				time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+			},
+			Values: []float64{1},
+			Err:    false,
+		},
+		{
+			Currency: "CHF",
+			From:     time.Date(2016, time.December, 1, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.December, 31, 0, 0, 0, 0, time.UTC),
+			Err:      false,
+		},
+		{
+			Currency: "USD",
+			From:     time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Err:      true,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0)
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		dates, values, err := client.RateSlices(test.Currency, test.From, test.To)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(test.Dates, dates) || !reflect.DeepEqual(test.Values, values) {
+			t.Errorf("Values `%v %v` and `%v %v` are not equal (i:%d)", test.Dates, test.Values, dates, values, i)
+		}
+	}
+}
+
+func TestCrossRateMatrix(t *testing.T) {
+	tests := []struct {
+		Date     time.Time
+		Expected map[string]map[string]float64
+		Err      bool
+	}{
+		{
+			Date: time.Date(2016, time.November, 9, 23, 0, 0, 0, time.UTC),
+			Expected: map[string]map[string]float64{
+				"EUR": {"EUR": 1, "USD": 3},
+				"USD": {"EUR": 0.3333, "USD": 1},
+			},
+			Err: false,
+		},
+		{
+			Date: time.Date(2016, time.November, 12, 23, 0, 0, 0, time.UTC),
+			Err:  true,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0)
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		matrix, err := client.CrossRateMatrix(test.Date)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			continue
+		}
+		if !reflect.DeepEqual(matrix, test.Expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", matrix, test.Expected, i)
+		}
+	}
+}
+
+func TestCrossRateMatrixMatchesConvert(t *testing.T) {
+	var reqUrl, reqMethod, reqBody string
+	handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), handler)
+	defer mock.Close()
+	date := time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC)
+	matrix, err := client.CrossRateMatrix(date)
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	if len(matrix) != 5 {
+		t.Errorf("Values `%v` and `%v` are not equal", len(matrix), 5)
+	}
+	for source, row := range matrix {
+		for target, rate := range row {
+			res, err := client.ConvertDetailed(1, source, target, date)
+			if err != nil {
+				t.Errorf("Want err == nil; got %v (%s/%s)", err, source, target)
+				continue
+			}
+			if res.Rate != rate {
+				t.Errorf("Values `%v` and `%v` are not equal (%s/%s)", res.Rate, rate, source, target)
+			}
+		}
+	}
+}
+
+func TestConvertRange(t *testing.T) {
+	tests := []struct {
+		Amount   float64
+		From     time.Time
+		To       time.Time
+		Expected map[time.Time]float64
+		Err      bool
+	}{
+		{
+			Amount: 10,
+			From:   time.Date(2016, time.November, 8, 23, 0, 0, 0, time.UTC),
+			To:     time.Date(2016, time.November, 12, 23, 0, 0, 0, time.UTC),
+			Expected: map[time.Time]float64{
+				time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC): 3.2012,
+				time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC): 3.2036,
+			},
+			Err: false,
+		},
+		{
+			Amount:   10,
+			From:     time.Date(2016, time.November, 1, 23, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.November, 9, 23, 0, 0, 0, time.UTC),
+			Expected: map[time.Time]float64{},
+			Err:      false,
+		},
+		{
+			Amount: 10,
+			From:   time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			To:     time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC),
+			Err:    true,
+		},
+		{
+			Amount: -10,
+			From:   time.Date(2016, time.November, 8, 23, 0, 0, 0, time.UTC),
+			To:     time.Date(2016, time.November, 12, 23, 0, 0, 0, time.UTC),
+			Err:    true,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0)
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		results, err := client.ConvertRange(test.Amount, "USD", "PLN", test.From, test.To)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			continue
+		}
+		if !reflect.DeepEqual(results, test.Expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", results, test.Expected, i)
+		}
+	}
+}
+
+func TestRateSlicesCaseInsensitive(t *testing.T) {
+	var reqUrl, reqMethod, reqBody string
+	handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), handler)
+	defer mock.Close()
+	from := time.Date(2016, time.November, 8, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	_, lower, err := client.RateSlices("usd", from, to)
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	_, upper, err := client.RateSlices("USD", from, to)
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	if len(lower) == 0 || !reflect.DeepEqual(lower, upper) {
+		t.Errorf("Values `%v` and `%v` are not equal", lower, upper)
+	}
+}
+
+func TestFetchBase(t *testing.T) {
+	tests := []struct {
+		Base     string
+		Date     time.Time
+		Expected euroxref.ExchangeRates
+		Err      bool
+	}{
+		{
+			Base: "usd",
+			Date: time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Expected: euroxref.ExchangeRates{
+				{Currency: "EUR", Rate: 0.998},
+				{Currency: "USD", Rate: 1},
+				{Currency: "CHF", Rate: 1.0279},
+				{Currency: "PLN", Rate: 0.3204},
+				{Currency: "XYZ", Rate: 1.996},
+			},
+			Err: false,
+		},
+		{
+			Base: "EUR",
+			Date: time.Date(2016, time.November, 9, 23, 0, 0, 0, time.UTC),
+			Expected: euroxref.ExchangeRates{
+				{Currency: "EUR", Rate: 1},
+				{Currency: "USD", Rate: 3},
+			},
+			Err: false,
+		},
+		{
+			Base: "CHF",
+			Date: time.Date(2016, time.November, 9, 23, 0, 0, 0, time.UTC),
+			Err:  true,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0)
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		res, err := client.FetchBase(test.Date, test.Base)
+		if test.Err {
+			var cErr *euroxref.ErrCurrencyNotFound
+			if !errors.As(err, &cErr) {
+				t.Errorf("Want *ErrCurrencyNotFound; got %v (i:%d)", err, i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(res, test.Expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+}
+
+func TestRateTable(t *testing.T) {
+	tests := []struct {
+		Date     time.Time
+		Expected euroxref.ExchangeRates
+		Err      bool
+	}{
+		{
+			Date: time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Expected: euroxref.ExchangeRates{
+				{Currency: "EUR", Rate: 1},
+				{Currency: "CHF", Rate: 1.03},
+				{Currency: "PLN", Rate: 0.321},
+				{Currency: "USD", Rate: 1.002},
+				{Currency: "XYZ", Rate: 1.9999999},
+			},
+			Err: false,
+		},
+		{
+			Date: time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Expected: euroxref.ExchangeRates{
+				{Currency: "EUR", Rate: 1},
+				{Currency: "PLN", Rate: 0.3211231231},
+				{Currency: "USD", Rate: 1.003123142},
+				{Currency: "XYZ", Rate: 2.00001999},
+			},
+			Err: false,
+		},
+		{
+			Date: time.Date(2016, time.November, 12, 0, 0, 0, 0, time.UTC),
+			Err:  true,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 60)
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		res, err := client.RateTable(test.Date)
+		mock.Close()
+		if test.Err {
+			var dErr *euroxref.ErrDateNotFound
+			if !errors.As(err, &dErr) {
+				t.Errorf("Want *ErrDateNotFound; got %v (i:%d)", err, i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(test.Expected, res) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.Expected, res, i)
+		}
+	}
+}
+
+func TestDateRange(t *testing.T) {
+	tests := []struct {
+		Response *euroxref.XRefRawResponse
+		Earliest time.Time
+		Latest   time.Time
+		Err      bool
+	}{
+		{
+			Response: testResponse,
+			Earliest: time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+			Latest:   time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Err:      false,
+		},
+		{
+			Response: &euroxref.XRefRawResponse{
+				Data: []euroxref.XRefRawData{
+					{RateTime: "2016-11-08", Rates: []euroxref.RawExchangeRate{}},
+				},
+			},
+			Err: true,
+		},
+	}
+	for i, test := range tests {
+		client := euroxref.New(4, 0)
+		mock := MockServer(t, client.(*euroxref.Client), xmlHandle(test.Response))
+		earliest, latest, err := client.DateRange()
+		mock.Close()
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !earliest.Equal(test.Earliest) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", earliest, test.Earliest, i)
+		}
+		if !latest.Equal(test.Latest) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", latest, test.Latest, i)
+		}
+	}
+}
+
+func TestPublishedDates(t *testing.T) {
+	tests := []struct {
+		From     time.Time
+		To       time.Time
+		Expected []time.Time
+		Err      bool
+	}{
+		{
+			From: time.Date(2016, time.November, 1, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2016, time.November, 30, 0, 0, 0, 0, time.UTC),
+			Expected: []time.Time{
+				time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+				time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+				time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			},
+			Err: false,
+		},
+		{
+			From: time.Date(2016, time.November, 8, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Expected: []time.Time{
+				time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+				time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			},
+			Err: false,
+		},
+		{
+			From:     time.Date(2016, time.November, 12, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.November, 13, 0, 0, 0, 0, time.UTC),
+			Expected: nil,
+			Err:      false,
+		},
+		{
+			From: time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+			Err:  true,
+		},
+	}
+	for i, test := range tests {
+		client := euroxref.New(4, 0)
+		mock := MockServer(t, client.(*euroxref.Client), xmlHandle(testResponse))
+		dates, err := client.PublishedDates(test.From, test.To)
+		mock.Close()
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(dates, test.Expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", dates, test.Expected, i)
+		}
+	}
+}
+
+func TestPercentChange(t *testing.T) {
+	tests := []struct {
+		Currency string
+		From     time.Time
+		To       time.Time
+		Pct      float64
+		Err      bool
+	}{
+		{
+			Currency: "USD",
+			From:     time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Pct:      -0.1097,
+			Err:      false,
+		},
+		{
+			Currency: "pln",
+			From:     time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Pct:      -0.0311,
+			Err:      false,
+		},
+		{
+			Currency: "USD",
+			From:     time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+			Pct:      199.4012,
+			Err:      false,
+		},
+		{
+			Currency: "CHF",
+			From:     time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Err:      true,
+		},
+		{
+			Currency: "USD",
+			From:     time.Date(2016, time.November, 8, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Err:      true,
+		},
+	}
+	for i, test := range tests {
+		client := euroxref.New(4, 0)
+		mock := MockServer(t, client.(*euroxref.Client), xmlHandle(testResponse))
+		pct, err := client.PercentChange(test.Currency, test.From, test.To)
+		mock.Close()
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if pct != test.Pct {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", pct, test.Pct, i)
+		}
+	}
+}
+
+func TestFetchAllAligned(t *testing.T) {
+	var reqUrl, reqMethod, reqBody string
+	handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), handler)
+	defer mock.Close()
+	rates, currencies, err := client.FetchAllAligned()
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	expectedCurrencies := []string{"CHF", "PLN", "USD", "XYZ"}
+	if !reflect.DeepEqual(currencies, expectedCurrencies) {
+		t.Errorf("Values `%v` and `%v` are not equal", currencies, expectedCurrencies)
+	}
+	nan := math.NaN()
+	expected := map[time.Time]map[string]float64{
+		time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC):  {"CHF": nan, "PLN": nan, "USD": 2.999999, "XYZ": nan},
+		time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC): {"CHF": nan, "PLN": 0.3211231231, "USD": 1.003123142, "XYZ": 2.00001999},
+		time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC): {"CHF": 1.03, "PLN": 0.321, "USD": 1.002, "XYZ": 1.9999999},
+	}
+	if len(rates) != len(expected) {
+		t.Fatalf("Values `%v` and `%v` are not equal", rates, expected)
+	}
+	for date, day := range expected {
+		if len(rates[date]) != len(day) {
+			t.Errorf("Values `%v` and `%v` are not equal (%s)", rates[date], day, date)
+			continue
+		}
+		for currency, rate := range day {
+			res, ok := rates[date][currency]
+			if !ok || (math.IsNaN(rate) != math.IsNaN(res)) || (!math.IsNaN(rate) && rate != res) {
+				t.Errorf("Values `%v` and `%v` are not equal (%s %s)", res, rate, date, currency)
+			}
+		}
+	}
+}
+
+func TestMovingAverage(t *testing.T) {
+	tests := []struct {
+		Currency string
+		End      time.Time
+		Window   int
+		Expected float64
+		Err      bool
+	}{
+		{
+			Currency: "USD",
+			End:      time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Window:   3,
+			Expected: 1.6684,
+			Err:      false,
+		},
+		{
+			Currency: "usd",
+			End:      time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Window:   2,
+			Expected: 1.0026,
+			Err:      false,
+		},
+		{
+			Currency: "USD",
+			End:      time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Window:   2,
+			Expected: 2.0016,
+			Err:      false,
+		},
+		{
+			Currency: "PLN",
+			End:      time.Date(2016, time.November, 13, 0, 0, 0, 0, time.UTC),
+			Window:   2,
+			Expected: 0.3211,
+			Err:      false,
+		},
+		{
+			Currency: "EUR",
+			End:      time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Window:   3,
+			Expected: 1,
+			Err:      false,
+		},
+		{
+			Currency: "USD",
+			End:      time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Window:   3,
+			Err:      true,
+		},
+		{
+			Currency: "CHF",
+			End:      time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Window:   2,
+			Err:      true,
+		},
+		{
+			Currency: "USD",
+			End:      time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Window:   0,
+			Err:      true,
+		},
+	}
+	var reqUrl, reqMethod, reqBody string
+	handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+	client := euroxref.New(4, 60)
+	mock := MockServer(t, client.(*euroxref.Client), handler)
+	defer mock.Close()
+	for i, test := range tests {
+		res, err := client.MovingAverage(test.Currency, test.End, test.Window)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if res != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+}
+
+func TestRateExtremes(t *testing.T) {
+	tests := []struct {
+		Currency string
+		From     time.Time
+		To       time.Time
+		Min      float64
+		Max      float64
+		MinDate  time.Time
+		MaxDate  time.Time
+		Err      bool
+	}{
+		{
+			Currency: "USD",
+			From:     time.Date(2016, time.November, 1, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.November, 30, 0, 0, 0, 0, time.UTC),
+			Min:      1.002,
+			Max:      2.999999,
+			MinDate:  time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			MaxDate:  time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+			Err:      false,
+		},
+		{
+			Currency: "pln",
+			From:     time.Date(2016, time.November, 1, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.November, 30, 0, 0, 0, 0, time.UTC),
+			Min:      0.321,
+			Max:      0.3211231231,
+			MinDate:  time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			MaxDate:  time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Err:      false,
+		},
+		{
+			Currency: "EUR",
+			From:     time.Date(2016, time.November, 1, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.November, 30, 0, 0, 0, 0, time.UTC),
+			Min:      1,
+			Max:      1,
+			MinDate:  time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+			MaxDate:  time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+			Err:      false,
+		},
+		{
+			Currency: "CHF",
+			From:     time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Err:      true,
+		},
+		{
+			Currency: "USD",
+			From:     time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Err:      true,
+		},
+	}
+	var reqUrl, reqMethod, reqBody string
+	handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+	client := euroxref.New(4, 60)
+	mock := MockServer(t, client.(*euroxref.Client), handler)
+	defer mock.Close()
+	for i, test := range tests {
+		min, max, minDate, maxDate, err := client.RateExtremes(test.Currency, test.From, test.To)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if min != test.Min || max != test.Max || !minDate.Equal(test.MinDate) || !maxDate.Equal(test.MaxDate) {
+			t.Errorf("Values `%v %v %v %v` and `%v %v %v %v` are not equal (i:%d)", min, max, minDate, maxDate, test.Min, test.Max, test.MinDate, test.MaxDate, i)
+		}
+	}
+}
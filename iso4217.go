@@ -0,0 +1,88 @@
+package euroxref
+
+import (
+	"strings"
+)
+
+// iso4217Codes contains alphabetic codes of currencies defined by ISO 4217.
+var iso4217Codes = map[string]struct{}{
+	"AED": {}, "AFN": {}, "ALL": {}, "AMD": {}, "ANG": {}, "AOA": {}, "ARS": {}, "AUD": {}, "AWG": {}, "AZN": {},
+	"BAM": {}, "BBD": {}, "BDT": {}, "BGN": {}, "BHD": {}, "BIF": {}, "BMD": {}, "BND": {}, "BOB": {}, "BOV": {},
+	"BRL": {}, "BSD": {}, "BTN": {}, "BWP": {}, "BYN": {}, "BZD": {}, "CAD": {}, "CDF": {}, "CHE": {}, "CHF": {},
+	"CHW": {}, "CLF": {}, "CLP": {}, "CNY": {}, "COP": {}, "COU": {}, "CRC": {}, "CUC": {}, "CUP": {}, "CVE": {},
+	"CZK": {}, "DJF": {}, "DKK": {}, "DOP": {}, "DZD": {}, "EGP": {}, "ERN": {}, "ETB": {}, "EUR": {}, "FJD": {},
+	"FKP": {}, "GBP": {}, "GEL": {}, "GHS": {}, "GIP": {}, "GMD": {}, "GNF": {}, "GTQ": {}, "GYD": {}, "HKD": {},
+	"HNL": {}, "HTG": {}, "HUF": {}, "IDR": {}, "ILS": {}, "INR": {}, "IQD": {}, "IRR": {}, "ISK": {}, "JMD": {},
+	"JOD": {}, "JPY": {}, "KES": {}, "KGS": {}, "KHR": {}, "KMF": {}, "KPW": {}, "KRW": {}, "KWD": {}, "KYD": {},
+	"KZT": {}, "LAK": {}, "LBP": {}, "LKR": {}, "LRD": {}, "LSL": {}, "LYD": {}, "MAD": {}, "MDL": {}, "MGA": {},
+	"MKD": {}, "MMK": {}, "MNT": {}, "MOP": {}, "MRU": {}, "MUR": {}, "MVR": {}, "MWK": {}, "MXN": {}, "MXV": {},
+	"MYR": {}, "MZN": {}, "NAD": {}, "NGN": {}, "NIO": {}, "NOK": {}, "NPR": {}, "NZD": {}, "OMR": {}, "PAB": {},
+	"PEN": {}, "PGK": {}, "PHP": {}, "PKR": {}, "PLN": {}, "PYG": {}, "QAR": {}, "RON": {}, "RSD": {}, "RUB": {},
+	"RWF": {}, "SAR": {}, "SBD": {}, "SCR": {}, "SDG": {}, "SEK": {}, "SGD": {}, "SHP": {}, "SLE": {}, "SLL": {},
+	"SOS": {}, "SRD": {}, "SSP": {}, "STN": {}, "SVC": {}, "SYP": {}, "SZL": {}, "THB": {}, "TJS": {}, "TMT": {},
+	"TND": {}, "TOP": {}, "TRY": {}, "TTD": {}, "TWD": {}, "TZS": {}, "UAH": {}, "UGX": {}, "USD": {}, "USN": {},
+	"UYI": {}, "UYU": {}, "UYW": {}, "UZS": {}, "VED": {}, "VES": {}, "VND": {}, "VUV": {}, "WST": {}, "XAF": {},
+	"XAG": {}, "XAU": {}, "XBA": {}, "XBB": {}, "XBC": {}, "XBD": {}, "XCD": {}, "XCG": {}, "XDR": {}, "XOF": {},
+	"XPD": {}, "XPF": {}, "XPT": {}, "XSU": {}, "XTS": {}, "XUA": {}, "XXX": {}, "YER": {}, "ZAR": {}, "ZMW": {},
+	"ZWG": {}, "ZWL": {},
+	// Withdrawn codes of currencies for which European Central Bank published historical rates.
+	"CYP": {}, "EEK": {}, "HRK": {}, "LTL": {}, "LVL": {}, "MTL": {}, "ROL": {}, "SIT": {}, "SKK": {}, "TRL": {},
+}
+
+// iso4217MinorUnits contains number of decimal places of ISO 4217 currencies which don't use 2,
+// -1 marking codes such as precious metals for which minor units aren't applicable.
+var iso4217MinorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0, "RWF": 0,
+	"UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0, "TRL": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+	"XAG": -1, "XAU": -1, "XBA": -1, "XBB": -1, "XBC": -1, "XBD": -1, "XDR": -1, "XPD": -1, "XPT": -1, "XSU": -1,
+	"XTS": -1, "XUA": -1, "XXX": -1,
+}
+
+// CurrencyDecimals returns standard number of decimal places of ISO 4217 currency, regardless of case of the code,
+// e.g. 0 for JPY or 3 for BHD. ok is false for unknown codes and ones without minor units such as XAU.
+func CurrencyDecimals(code string) (decimals int, ok bool) {
+	code = strings.ToUpper(code)
+	if !IsValidCurrencyCode(code) {
+		return 0, false
+	}
+	if decimals, ok = iso4217MinorUnits[code]; !ok {
+		return 2, true
+	}
+	if decimals < 0 {
+		return 0, false
+	}
+	return decimals, true
+}
+
+// resultParams returns optional precision args under which conversion result into target currency is rounded,
+// standard decimal places of target currency if UseCurrencyDecimals is set and they're known, none otherwise.
+func (c *Client) resultParams(target string) []int {
+	if !c.UseCurrencyDecimals {
+		return nil
+	}
+	if decimals, ok := CurrencyDecimals(target); ok {
+		return []int{decimals}
+	}
+	return nil
+}
+
+// IsValidCurrencyCode checks if code is an ISO 4217 alphabetic currency code, regardless of its case.
+func IsValidCurrencyCode(code string) bool {
+	_, ok := iso4217Codes[strings.ToUpper(code)]
+	return ok
+}
+
+// checkCurrencies returns currency codes converted to upper case. If StrictCurrencies is set
+// *ErrInvalidCurrencyCode is returned for any code which isn't defined by ISO 4217.
+func (c *Client) checkCurrencies(codes ...string) ([]string, error) {
+	res := make([]string, len(codes))
+	for idx, code := range codes {
+		if c.StrictCurrencies && !IsValidCurrencyCode(code) {
+			return nil, &ErrInvalidCurrencyCode{Code: code}
+		}
+		res[idx] = strings.ToUpper(code)
+	}
+	return res, nil
+}
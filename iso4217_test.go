@@ -0,0 +1,222 @@
+package euroxref_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/exaroth/euroxref-konrad"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsValidCurrencyCode(t *testing.T) {
+	tests := []struct {
+		Code     string
+		Expected bool
+	}{
+		{Code: "USD", Expected: true},
+		{Code: "usd", Expected: true},
+		{Code: "EUR", Expected: true},
+		{Code: "SIT", Expected: true},
+		{Code: "XYZ", Expected: false},
+		{Code: "US", Expected: false},
+		{Code: "", Expected: false},
+	}
+	for i, test := range tests {
+		if res := euroxref.IsValidCurrencyCode(test.Code); res != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+}
+
+func TestStrictCurrencies(t *testing.T) {
+	tests := []struct {
+		Strict     bool
+		Currencies [2]string
+		Expected   float64
+		InvalidErr bool
+		Requests   int
+	}{
+		{
+			Strict:     true,
+			Currencies: [2]string{"usd", "Pln"},
+			Expected:   3.2036,
+			Requests:   1,
+		},
+		{
+			Strict:     true,
+			Currencies: [2]string{"USD", "XYZ"},
+			InvalidErr: true,
+			Requests:   0,
+		},
+		{
+			Strict:     false,
+			Currencies: [2]string{"USD", "XYZ"},
+			Expected:   19.9601,
+			Requests:   1,
+		},
+	}
+	for i, test := range tests {
+		requests := 0
+		client := euroxref.New(4, 0)
+		client.(*euroxref.Client).StrictCurrencies = test.Strict
+		mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+			requests++
+			xmlHandle(testResponse)(w, req)
+		})
+		res, err := client.Convert(10, test.Currencies[0], test.Currencies[1], time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC))
+		mock.Close()
+		if test.InvalidErr {
+			var cErr *euroxref.ErrInvalidCurrencyCode
+			if !errors.As(err, &cErr) {
+				t.Errorf("Want *ErrInvalidCurrencyCode; got %v (i:%d)", err, i)
+			}
+		} else if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		} else if res != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+		if requests != test.Requests {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", requests, test.Requests, i)
+		}
+	}
+}
+
+func TestCurrencyDecimals(t *testing.T) {
+	tests := []struct {
+		Code     string
+		Decimals int
+		Ok       bool
+	}{
+		{Code: "USD", Decimals: 2, Ok: true},
+		{Code: "jpy", Decimals: 0, Ok: true},
+		{Code: "BHD", Decimals: 3, Ok: true},
+		{Code: "CLF", Decimals: 4, Ok: true},
+		{Code: "EUR", Decimals: 2, Ok: true},
+		{Code: "XAU", Decimals: 0, Ok: false},
+		{Code: "XYZ", Decimals: 0, Ok: false},
+	}
+	for i, test := range tests {
+		decimals, ok := euroxref.CurrencyDecimals(test.Code)
+		if decimals != test.Decimals || ok != test.Ok {
+			t.Errorf("Values `%v %v` and `%v %v` are not equal (i:%d)", decimals, ok, test.Decimals, test.Ok, i)
+		}
+	}
+}
+
+func TestUseCurrencyDecimals(t *testing.T) {
+	tests := []struct {
+		Enabled    bool
+		Amount     float64
+		Currencies [2]string
+		Expected   float64
+	}{
+		{Enabled: true, Amount: 1000, Currencies: [2]string{"USD", "JPY"}, Expected: 273},
+		{Enabled: false, Amount: 1000, Currencies: [2]string{"USD", "JPY"}, Expected: 272.7273},
+		{Enabled: true, Amount: 1000, Currencies: [2]string{"JPY", "usd"}, Expected: 3666.67},
+		{Enabled: true, Amount: 0.7, Currencies: [2]string{"EUR", "SEK"}, Expected: 6.82},
+		{Enabled: true, Amount: 10.5, Currencies: [2]string{"EUR", "EUR"}, Expected: 10.5},
+	}
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	for i, test := range tests {
+		client := euroxref.New(4, 60)
+		client.(*euroxref.Client).UseCurrencyDecimals = test.Enabled
+		mock := MockServer(t, client.(*euroxref.Client), xmlHandle(decimalResponse))
+		res, err := client.Convert(test.Amount, test.Currencies[0], test.Currencies[1], date)
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if res != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+		detailed, err := client.ConvertDetailed(test.Amount, test.Currencies[0], test.Currencies[1], date)
+		mock.Close()
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if detailed.Result != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", detailed.Result, test.Expected, i)
+		}
+	}
+}
+
+func TestUseCurrencyDecimalsEntryPoints(t *testing.T) {
+	tests := []struct {
+		Amount     float64
+		Currencies [2]string
+	}{
+		{Amount: 1000, Currencies: [2]string{"USD", "JPY"}},
+		{Amount: 1000, Currencies: [2]string{"JPY", "usd"}},
+		{Amount: 0.7, Currencies: [2]string{"EUR", "SEK"}},
+		{Amount: 10.5, Currencies: [2]string{"EUR", "EUR"}},
+	}
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	for i, test := range tests {
+		source, target := test.Currencies[0], test.Currencies[1]
+		client := euroxref.New(4, 60)
+		client.(*euroxref.Client).UseCurrencyDecimals = true
+		mock := MockServer(t, client.(*euroxref.Client), xmlHandle(decimalResponse))
+		expected, err := client.Convert(test.Amount, source, target, date)
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		results := make(map[string]float64)
+		var errs []error
+		collect := func(name string, res float64, err error) {
+			results[name] = res
+			errs = append(errs, err)
+		}
+		parse := func(name string, res string, err error) {
+			value, parseErr := strconv.ParseFloat(res, 64)
+			if err == nil {
+				err = parseErr
+			}
+			collect(name, value, err)
+		}
+		res, err := client.ConvertAt(test.Amount, source, target, "2016-11-11")
+		collect("ConvertAt", res, err)
+		res, err = client.ConvertWithSpread(test.Amount, source, target, date, 0)
+		collect("ConvertWithSpread", res, err)
+		res, err = client.ConvertVia(test.Amount, source, target, target, date)
+		collect("ConvertVia", res, err)
+		str, err := client.ConvertString(strconv.FormatFloat(test.Amount, 'f', -1, 64), source, target, date)
+		parse("ConvertString", str, err)
+		str, err = client.ConvertFormatted(test.Amount, source, target, date)
+		parse("ConvertFormatted", str, err)
+		detailed, err := client.ConvertDetailed(test.Amount, source, target, date)
+		collect("ConvertDetailed", detailed.Result, err)
+		many, err := client.ConvertMany(source, []euroxref.ConversionRequest{{Amount: test.Amount, Target: target}}, date)
+		if err == nil {
+			err = many[0].Err
+			res = many[0].Result
+		}
+		collect("ConvertMany", res, err)
+		byDate, err := client.ConvertRange(test.Amount, source, target, date, date)
+		collect("ConvertRange", byDate[date], err)
+		byTarget, err := client.ConvertWatchlist(test.Amount, source, map[string]int{target: 0}, date)
+		collect("ConvertWatchlist", byTarget[strings.ToUpper(target)], err)
+		byTarget, err = client.ConvertSplit(test.Amount, source, map[string]float64{target: 1}, date)
+		collect("ConvertSplit", byTarget[target], err)
+		var out bytes.Buffer
+		err = client.ConvertCSV(strings.NewReader(fmt.Sprintf("2016-11-11,%v,%s,%s\n", test.Amount, source, target)), &out)
+		row := strings.Split(strings.TrimSpace(out.String()), ",")
+		if err == nil && row[5] != "" {
+			err = errors.New(row[5])
+		}
+		parse("ConvertCSV", row[4], err)
+		mock.Close()
+		for _, err := range errs {
+			if err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			}
+		}
+		for name, res := range results {
+			if res != expected {
+				t.Errorf("Values `%v` and `%v` are not equal for %s (i:%d)", res, expected, name, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package euroxref
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// MarshalJSON encodes exchange rates in compact form of currency to rate mapping, e.g. {"USD":1.002}.
+// Array of {"currency","rate"} objects can be produced by marshaling []ExchangeRate(rates) instead.
+func (e ExchangeRates) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Map())
+}
+
+// UnmarshalJSON decodes exchange rates from either form, rates given as a mapping are sorted by currency.
+func (e *ExchangeRates) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var rates []ExchangeRate
+		if err := json.Unmarshal(trimmed, &rates); err != nil {
+			return err
+		}
+		*e = rates
+		return nil
+	}
+	var m map[string]float64
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	if m == nil {
+		*e = nil
+		return nil
+	}
+	rates := make(ExchangeRates, 0, len(m))
+	for currency, rate := range m {
+		rates = append(rates, ExchangeRate{Currency: currency, Rate: rate})
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].Currency < rates[j].Currency
+	})
+	*e = rates
+	return nil
+}
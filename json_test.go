@@ -0,0 +1,82 @@
+package euroxref_test
+
+import (
+	"encoding/json"
+	"github.com/exaroth/euroxref-konrad"
+	"reflect"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	rates := euroxref.ExchangeRates{
+		{Currency: "USD", Rate: 1.002},
+		{Currency: "CHF", Rate: 1.03},
+	}
+	tests := []struct {
+		Value    interface{}
+		Expected string
+	}{
+		{
+			Value:    rates,
+			Expected: `{"CHF":1.03,"USD":1.002}`,
+		},
+		{
+			Value:    []euroxref.ExchangeRate(rates),
+			Expected: `[{"currency":"USD","rate":1.002},{"currency":"CHF","rate":1.03}]`,
+		},
+		{
+			Value:    rates[0],
+			Expected: `{"currency":"USD","rate":1.002}`,
+		},
+		{
+			Value:    euroxref.ExchangeRates{},
+			Expected: `{}`,
+		},
+	}
+	for i, test := range tests {
+		res, err := json.Marshal(test.Value)
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if string(res) != test.Expected {
+			t.Errorf("Values `%s` and `%s` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		Input    string
+		Expected euroxref.ExchangeRates
+		Err      bool
+	}{
+		{
+			Input:    `{"USD":1.002,"CHF":1.03}`,
+			Expected: euroxref.ExchangeRates{{Currency: "CHF", Rate: 1.03}, {Currency: "USD", Rate: 1.002}},
+		},
+		{
+			Input:    ` [{"currency":"USD","rate":1.002},{"currency":"CHF","rate":1.03}]`,
+			Expected: euroxref.ExchangeRates{{Currency: "USD", Rate: 1.002}, {Currency: "CHF", Rate: 1.03}},
+		},
+		{
+			Input: `"USD"`,
+			Err:   true,
+		},
+	}
+	for i, test := range tests {
+		var res euroxref.ExchangeRates
+		err := json.Unmarshal([]byte(test.Input), &res)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(res, test.Expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package euroxref_test
+
+import (
+	"github.com/exaroth/euroxref-konrad"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// namespacedResponses hold the same data using namespaces as published by ECB, prefixed namespaces and no namespaces.
+var namespacedResponses = []string{
+	`<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time="2016-11-11">
+			<Cube currency="USD" rate="1.0904"/>
+			<Cube currency="JPY" rate="115.53"/>
+		</Cube>
+		<Cube time="2016-11-10">
+			<Cube currency="USD" rate="1.0882"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`,
+	`<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns:ecb="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<ecb:Cube>
+		<ecb:Cube ecb:time="2016-11-11">
+			<ecb:Cube ecb:currency="USD" ecb:rate="1.0904"/>
+			<ecb:Cube ecb:currency="JPY" ecb:rate="115.53"/>
+		</ecb:Cube>
+		<ecb:Cube ecb:time="2016-11-10">
+			<ecb:Cube ecb:currency="USD" ecb:rate="1.0882"/>
+		</ecb:Cube>
+	</ecb:Cube>
+</gesmes:Envelope>`,
+	`<Envelope>
+	<Cube>
+		<Cube time="2016-11-11">
+			<Cube currency="USD" rate="1.0904"/>
+			<Cube currency="JPY" rate="115.53"/>
+		</Cube>
+		<Cube time="2016-11-10">
+			<Cube currency="USD" rate="1.0882"/>
+		</Cube>
+	</Cube>
+</Envelope>`,
+}
+
+func TestNamespacedXML(t *testing.T) {
+	expected := map[time.Time]euroxref.ExchangeRates{
+		time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC): {{Currency: "USD", Rate: 1.0904}, {Currency: "JPY", Rate: 115.53}},
+		time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC): {{Currency: "USD", Rate: 1.0882}},
+	}
+	for i, body := range namespacedResponses {
+		client := euroxref.New(4, 60)
+		mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+			w.Write([]byte(body))
+		})
+		all, err := client.FetchAll()
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(all, expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", all, expected, i)
+		}
+		streamed := make(map[time.Time]euroxref.ExchangeRates)
+		err = client.FetchStream(func(date time.Time, rates euroxref.ExchangeRates) error {
+			streamed[date] = rates
+			return nil
+		})
+		mock.Close()
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(streamed, expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", streamed, expected, i)
+		}
+		data, err := euroxref.ParseXML(strings.NewReader(body))
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		} else if len(data.Data) != len(expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", len(data.Data), len(expected), i)
+		}
+	}
+}
+
+func TestStrictXML(t *testing.T) {
+	tests := []struct {
+		Body string
+		Err  string
+	}{
+		{
+			Body: namespacedResponses[0],
+		},
+		{
+			Body: namespacedResponses[1],
+		},
+		{
+			Body: namespacedResponses[2],
+		},
+		{
+			Body: `<html><head><title>Maintenance</title></head><body><p>Back soon</p></body></html>`,
+			Err:  "Unexpected root element <html>",
+		},
+		{
+			Body: `<!DOCTYPE html><html><body><br></body></html>`,
+			Err:  "XML syntax error",
+		},
+		{
+			Body: `<Envelope><Cube></Cube></Envelope>`,
+			Err:  "No days found",
+		},
+		{
+			Body: `<Envelope><Cube><Cube time="11/11/2016"><Cube currency="USD" rate="1.0904"/></Cube></Cube></Envelope>`,
+			Err:  `Invalid date "11/11/2016"`,
+		},
+		{
+			Body: `<Envelope><Cube><Cube time="2016-11-11"><Cube currency="USD" rate="1.0904"/></Cube><Cube time="2016-11-10"></Cube></Cube></Envelope>`,
+			Err:  "No rates found for 2016-11-10",
+		},
+	}
+	for i, test := range tests {
+		client := euroxref.New(4, 60)
+		client.(*euroxref.Client).StrictXML = true
+		mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+			w.Write([]byte(test.Body))
+		})
+		_, err := client.FetchAll()
+		mock.Close()
+		if test.Err == "" {
+			if err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.Err) {
+			t.Errorf("Want error containing %q; got %v (i:%d)", test.Err, err, i)
+		}
+	}
+	// Without strict mode unexpected document simply holds no rates.
+	client := euroxref.New(4, 60)
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(tests[3].Body))
+	})
+	defer mock.Close()
+	if all, err := client.FetchAll(); err != nil || len(all) != 0 {
+		t.Errorf("Want no rates and err == nil; got %v, %v", all, err)
+	}
+}
@@ -0,0 +1,135 @@
+package euroxref
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ParseXML decodes exchange rate data in ECB XML format from r, e.g. from a file downloaded beforehand.
+// Data is decoded the same way as when retrieved by the client, so that xml.Marshal of the result
+// produces data which can be parsed again.
+func ParseXML(r io.Reader) (data *XRefRawResponse, err error) {
+	data = &XRefRawResponse{}
+	if err = xml.NewDecoder(r).Decode(data); err != nil {
+		return nil, err
+	}
+	if len(data.Data) == 0 {
+		return nil, errors.New("No exchange rate data found")
+	}
+	dedupeDays(data)
+	return data, nil
+}
+
+// LoadFrom populates client with exchange rate data in ECB XML format read from r instead of retrieving it
+// from SourceURL, which allows using the client without network access. Loaded data never expires,
+// so it's served until Refresh is called or other data is loaded.
+func (c *Client) LoadFrom(r io.Reader) error {
+	data, err := ParseXML(r)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClientClosed
+	}
+	jumps := c.detectJumps(c.XRefData, data)
+	c.XRefData = data
+	c.parsed = nil
+	c.lastFetched = time.Now()
+	c.cachedUntil = neverExpires
+	c.mu.Unlock()
+	c.reportJumps(jumps)
+	return nil
+}
+
+// Snapshot writes exchange rate data held by the client to w in ECB XML format, retrieving it first
+// if it isn't fresh. Output is deterministic for the same data, and can be loaded again with Restore,
+// e.g. to record the dataset once and replay it in tests without network access.
+func (c *Client) Snapshot(w io.Writer) (err error) {
+	if err = c.fetchXML(context.Background()); err != nil {
+		return
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, err = io.WriteString(w, xml.Header); err != nil {
+		return
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err = enc.Encode(c.XRefData); err != nil {
+		return
+	}
+	_, err = io.WriteString(w, "\n")
+	return
+}
+
+// Restore loads exchange rate data written by Snapshot from r, same as LoadFrom.
+func (c *Client) Restore(r io.Reader) error {
+	return c.LoadFrom(r)
+}
+
+// SetFallback sets exchange rate data in ECB XML format read from r as last known good snapshot, e.g. one bundled
+// with the application. Snapshot is served only while data can't be retrieved and client holds no live data,
+// in which case Stale reports true. Retrieval of live data is still attempted whenever data is needed,
+// so snapshot is replaced as soon as it succeeds.
+func (c *Client) SetFallback(r io.Reader) error {
+	data, err := ParseXML(r)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fallback = data
+	return nil
+}
+
+// Stale reports whether client serves exchange rates from the snapshot set by SetFallback
+// because live data couldn't be retrieved. It never triggers retrieval of the data.
+func (c *Client) Stale() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.XRefData != nil && c.XRefData == c.fallback
+}
+
+// RatesFromXML returns exchange rates for given day from data in ECB XML format read from r,
+// rounded to prec digits same as with WithRoundedRates. It's a stateless helper for data which
+// already exists in memory, nothing is retrieved nor cached.
+func RatesFromXML(r io.Reader, t time.Time, prec int) (rates ExchangeRates, err error) {
+	c, err := offlineClient(r, prec, WithRoundedRates())
+	if err != nil {
+		return
+	}
+	return c.Fetch(t)
+}
+
+// ConvertFromXML converts amount of source currency into target currency using exchange rates for given day
+// from data in ECB XML format read from r, rounding the result to prec digits same as ConvertPrec.
+// It's a stateless helper for data which already exists in memory, nothing is retrieved nor cached.
+func ConvertFromXML(r io.Reader, amount float64, source, target string, t time.Time, prec int) (result float64, err error) {
+	c, err := offlineClient(r, prec)
+	if err != nil {
+		return
+	}
+	return c.ConvertPrec(amount, source, target, t, prec)
+}
+
+// offlineClient returns client with given precision serving data read from r, which never retrieves any data.
+func offlineClient(r io.Reader, prec int, opts ...Option) (c *Client, err error) {
+	if prec < 0 || prec > MaxPrecision {
+		return nil, &ValidationError{
+			Param:  "prec",
+			Value:  prec,
+			Reason: fmt.Sprintf("must be between 0 and %d", MaxPrecision),
+		}
+	}
+	c = NewClient(uint(prec), 0, opts...)
+	if err = c.LoadFrom(r); err != nil {
+		return nil, err
+	}
+	return
+}
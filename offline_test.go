@@ -0,0 +1,351 @@
+package euroxref_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"github.com/exaroth/euroxref-konrad"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseXML(t *testing.T) {
+	raw, err := xml.Marshal(testResponse)
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	tests := []struct {
+		Body     string
+		Expected *euroxref.XRefRawResponse
+		Err      bool
+	}{
+		{
+			Body:     string(raw),
+			Expected: testResponse,
+			Err:      false,
+		},
+		{
+			Body: `<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">` +
+				`<Cube><Cube time="2016-11-11"><Cube currency="USD" rate="1.002"/></Cube></Cube></gesmes:Envelope>`,
+			Expected: &euroxref.XRefRawResponse{
+				Data: []euroxref.XRefRawData{
+					{RateTime: "2016-11-11", Rates: []euroxref.RawExchangeRate{{Currency: "USD", Rate: "1.002"}}},
+				},
+			},
+			Err: false,
+		},
+		{
+			Body: `<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01"><Cube></Cube></gesmes:Envelope>`,
+			Err:  true,
+		},
+		{
+			Body: `<gesmes:Envelope`,
+			Err:  true,
+		},
+	}
+	for i, test := range tests {
+		res, err := euroxref.ParseXML(strings.NewReader(test.Body))
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			continue
+		}
+		// Data is compared in marshalled form as days without rates decode to nil slices.
+		got, _ := xml.Marshal(res.Data)
+		want, _ := xml.Marshal(test.Expected.Data)
+		if !bytes.Equal(got, want) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res.Data, test.Expected.Data, i)
+		}
+	}
+}
+
+func TestLoadFrom(t *testing.T) {
+	raw, err := xml.Marshal(testResponse)
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	requests := 0
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+	})
+	defer mock.Close()
+	if err := client.LoadFrom(bytes.NewReader(raw)); err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	res, err := client.Convert(10, "CHF", "USD", time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Errorf("Want err == nil; got %v", err)
+	}
+	if res != 9.7282 {
+		t.Errorf("Values `%v` and `%v` are not equal", res, 9.7282)
+	}
+	if requests != 0 {
+		t.Errorf("Want 0 requests; got %d", requests)
+	}
+	if !client.Ready() {
+		t.Errorf("Want client to be ready after loading data")
+	}
+	if err := client.LoadFrom(strings.NewReader("")); err == nil {
+		t.Errorf("Want err != nil; got nil")
+	}
+	client.Close()
+	if err := client.LoadFrom(bytes.NewReader(raw)); !errors.Is(err, euroxref.ErrClientClosed) {
+		t.Errorf("Want ErrClientClosed; got %v", err)
+	}
+}
+
+func TestSetFallback(t *testing.T) {
+	raw, err := xml.Marshal(testResponse)
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	available := false
+	requests := 0
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		if !available {
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		xmlHandle(revisedResponse)(w, req)
+	})
+	defer mock.Close()
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	if _, err := client.Convert(10, "EUR", "USD", date); err == nil {
+		t.Errorf("Want err != nil without fallback; got nil")
+	}
+	if err := client.SetFallback(strings.NewReader("<Envelope/>")); err == nil {
+		t.Errorf("Want err != nil for empty snapshot; got nil")
+	}
+	if err := client.SetFallback(bytes.NewReader(raw)); err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		res, err := client.ConvertDetailed(10, "EUR", "USD", date)
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if res.Result != 10.02 || !res.Stale {
+			t.Errorf("Want stale result 10.02; got %+v (i:%d)", res, i)
+		}
+		if !client.Stale() {
+			t.Errorf("Want client to serve stale data (i:%d)", i)
+		}
+	}
+	if requests != 3 {
+		t.Errorf("Want 3 requests; got %d", requests)
+	}
+	available = true
+	res, err := client.ConvertDetailed(10, "EUR", "USD", date)
+	if err != nil {
+		t.Errorf("Want err == nil; got %v", err)
+	}
+	if res.Result != 11 || res.Stale {
+		t.Errorf("Want live result 11; got %+v", res)
+	}
+	if client.Stale() {
+		t.Errorf("Want client to serve live data")
+	}
+	// Live data which can't be refreshed isn't replaced by the snapshot.
+	available = false
+	if _, err := client.Convert(10, "EUR", "USD", date); err == nil {
+		t.Errorf("Want err != nil; got nil")
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	client := euroxref.New(4, 60)
+	mock := MockServer(t, client.(*euroxref.Client), xmlHandle(testResponse))
+	var first, second bytes.Buffer
+	if err := client.Snapshot(&first); err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	if err := client.Snapshot(&second); err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	expected, err := client.FetchAll()
+	mock.Close()
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Errorf("Values `%s` and `%s` are not equal", first.String(), second.String())
+	}
+	if !strings.HasPrefix(first.String(), xml.Header) {
+		t.Errorf("Want snapshot to start with XML header; got %s", first.String())
+	}
+
+	requests := 0
+	restored := euroxref.New(4, 0)
+	mock = MockServer(t, restored.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+	})
+	defer mock.Close()
+	if err := restored.Restore(&first); err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	res, err := restored.FetchAll()
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("Values `%v` and `%v` are not equal", res, expected)
+	}
+	if requests != 0 {
+		t.Errorf("Want no requests; got %d", requests)
+	}
+}
+
+func TestSnapshotUnavailable(t *testing.T) {
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+	})
+	defer mock.Close()
+	var out bytes.Buffer
+	if err := client.Snapshot(&out); err == nil {
+		t.Errorf("Want err != nil; got nil")
+	}
+	if out.Len() != 0 {
+		t.Errorf("Want nothing written; got %s", out.String())
+	}
+}
+
+func TestRatesFromXML(t *testing.T) {
+	raw, err := xml.Marshal(testResponse)
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	tests := []struct {
+		Body      string
+		Date      time.Time
+		Precision int
+		Expected  euroxref.ExchangeRates
+		Err       bool
+	}{
+		{
+			Body:      string(raw),
+			Date:      time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Precision: 4,
+			Expected: euroxref.ExchangeRates{
+				{Currency: "USD", Rate: 1.0031},
+				{Currency: "PLN", Rate: 0.3211},
+				{Currency: "XYZ", Rate: 2},
+			},
+			Err: false,
+		},
+		{
+			Body:      string(raw),
+			Date:      time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Precision: euroxref.MaxPrecision,
+			Expected: euroxref.ExchangeRates{
+				{Currency: "USD", Rate: 1.003123142},
+				{Currency: "PLN", Rate: 0.3211231231},
+				{Currency: "XYZ", Rate: 2.00001999},
+			},
+			Err: false,
+		},
+		{
+			Body:      string(raw),
+			Date:      time.Date(2016, time.November, 12, 0, 0, 0, 0, time.UTC),
+			Precision: 4,
+			Err:       true,
+		},
+		{
+			Body:      string(raw),
+			Date:      time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Precision: -1,
+			Err:       true,
+		},
+		{
+			Body:      `<gesmes:Envelope`,
+			Date:      time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+			Precision: 4,
+			Err:       true,
+		},
+	}
+	for i, test := range tests {
+		res, err := euroxref.RatesFromXML(strings.NewReader(test.Body), test.Date, test.Precision)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(res, test.Expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+}
+
+func TestConvertFromXML(t *testing.T) {
+	raw, err := xml.Marshal(testResponse)
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	tests := []struct {
+		Amount     float64
+		Currencies [2]string
+		Precision  int
+		Expected   float64
+		Err        bool
+	}{
+		{
+			Amount:     10,
+			Currencies: [2]string{"USD", "CHF"},
+			Precision:  4,
+			Expected:   10.2794,
+			Err:        false,
+		},
+		{
+			Amount:     10,
+			Currencies: [2]string{"eur", "pln"},
+			Precision:  0,
+			Expected:   3,
+			Err:        false,
+		},
+		{
+			Amount:     10,
+			Currencies: [2]string{"USD", "ABC"},
+			Precision:  4,
+			Err:        true,
+		},
+		{
+			Amount:     10,
+			Currencies: [2]string{"USD", "CHF"},
+			Precision:  euroxref.MaxPrecision + 1,
+			Err:        true,
+		},
+	}
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	for i, test := range tests {
+		res, err := euroxref.ConvertFromXML(bytes.NewReader(raw), test.Amount, test.Currencies[0], test.Currencies[1], date, test.Precision)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if res != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package euroxref
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Option configures optional Client behaviour, passed to New and NewValidated.
+type Option func(*Client)
+
+// WithRoundingIncrement makes final conversion results rounded to the nearest multiple of inc
+// (e.g. 0.05 for cash handling), increment of 0 disables it.
+// Precision of the client should be high enough to represent the increment.
+func WithRoundingIncrement(inc float64) Option {
+	return func(c *Client) {
+		c.roundingIncrement = inc
+	}
+}
+
+// WithMaxFallbackDays limits how many days back fallback to earlier dates can go
+// (FetchNearest and FallbackToPrevious), 0 means no limit.
+func WithMaxFallbackDays(n int) Option {
+	return func(c *Client) {
+		c.maxFallbackDays = n
+	}
+}
+
+// WithAmountPrecision sets precision to which amounts are rounded before conversion.
+// Historically amounts are rounded to cents (DefaultAmountPrecision), which loses
+// fractional input such as 10.555, set it to MaxPrecision to effectively disable the rounding.
+// Values below 1 restore the default.
+func WithAmountPrecision(prec int) Option {
+	return func(c *Client) {
+		c.amountPrec = prec
+	}
+}
+
+// WithRoundedRates makes client round exchange rates to client precision as soon as they're parsed,
+// so that rates returned by Fetch and used for conversions are rounded same as in earlier versions.
+// By default rates keep full precision published by ECB and conversions round only the final result.
+func WithRoundedRates() Option {
+	return func(c *Client) {
+		c.roundRates = true
+	}
+}
+
+// WithSourceURL makes client retrieve exchange rate data from rawURL instead of ECB 90 day feed,
+// e.g. from a local mirror serving the same XML schema.
+func WithSourceURL(rawURL string) Option {
+	return func(c *Client) {
+		c.SourceURL = rawURL
+	}
+}
+
+// WithHTTPClient makes client retrieve data using hc, e.g. one with custom timeout.
+// Passing nil falls back to a dedicated client limited by DefaultTimeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc == nil {
+			hc = &http.Client{Timeout: DefaultTimeout}
+		}
+		c.HTTPClient = hc
+	}
+}
+
+// WithTransport makes client send requests using rt, e.g. an instrumented transport used for tracing.
+// HTTP client configured so far is copied rather than modified, so shared clients such as
+// http.DefaultClient are left intact. Passing nil falls back to http.DefaultTransport.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		hc := &http.Client{}
+		if c.HTTPClient != nil {
+			*hc = *c.HTTPClient
+		}
+		hc.Transport = rt
+		c.HTTPClient = hc
+	}
+}
+
+// WithTimeout sets time limit for retrieving exchange rate data, DefaultTimeout by default, 0 means no limit.
+// Same as WithTransport HTTP client configured so far is copied rather than modified.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		hc := &http.Client{}
+		if c.HTTPClient != nil {
+			*hc = *c.HTTPClient
+		}
+		hc.Timeout = d
+		c.HTTPClient = hc
+	}
+}
+
+// isValidSourceURL checks if rawURL is an absolute http(s) url.
+func isValidSourceURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
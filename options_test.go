@@ -0,0 +1,268 @@
+package euroxref_test
+
+import (
+	"errors"
+	"github.com/exaroth/euroxref-konrad"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoundingIncrement(t *testing.T) {
+	tests := []struct {
+		Date       time.Time
+		Amount     float64
+		Increment  float64
+		Currencies [2]string
+		Expected   float64
+	}{
+		{
+			Date:       time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount:     10,
+			Increment:  0,
+			Currencies: [2]string{"CHF", "USD"},
+			Expected:   9.7282,
+		},
+		{
+			Date:       time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount:     10,
+			Increment:  0.05,
+			Currencies: [2]string{"CHF", "USD"},
+			Expected:   9.75,
+		},
+		{
+			Date:       time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount:     10,
+			Increment:  0.25,
+			Currencies: [2]string{"CHF", "USD"},
+			Expected:   9.75,
+		},
+		{
+			Date:       time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC),
+			Amount:     10,
+			Increment:  0.05,
+			Currencies: [2]string{"USD", "XYZ"},
+			Expected:   19.95,
+		},
+		{
+			Date:       time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC),
+			Amount:     10,
+			Increment:  0.25,
+			Currencies: [2]string{"USD", "XYZ"},
+			Expected:   20,
+		},
+		{
+			Date:       time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount:     10,
+			Increment:  0.05,
+			Currencies: [2]string{"PLN", "CHF"},
+			Expected:   32.1,
+		},
+		{
+			Date:       time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount:     10,
+			Increment:  0.25,
+			Currencies: [2]string{"PLN", "CHF"},
+			Expected:   32,
+		},
+		{
+			Date:       time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount:     10.12,
+			Increment:  0.05,
+			Currencies: [2]string{"EUR", "EUR"},
+			Expected:   10.1,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0, euroxref.WithRoundingIncrement(test.Increment))
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		res, err := client.Convert(test.Amount, test.Currencies[0], test.Currencies[1], test.Date)
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if test.Expected != res {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.Expected, res, i)
+		}
+	}
+	if _, err := euroxref.NewValidated(4, 0, euroxref.WithRoundingIncrement(-0.05)); err == nil {
+		t.Errorf("Want err != nil for negative increment; got nil")
+	}
+}
+
+func TestAmountPrecision(t *testing.T) {
+	tests := []struct {
+		Amount          float64
+		AmountPrecision int
+		Expected        float64
+	}{
+		{
+			Amount:          10.555,
+			AmountPrecision: 0,
+			Expected:        10.5811,
+		},
+		{
+			Amount:          10.555,
+			AmountPrecision: 2,
+			Expected:        10.5811,
+		},
+		{
+			Amount:          10.555,
+			AmountPrecision: euroxref.MaxPrecision,
+			Expected:        10.5761,
+		},
+		{
+			Amount:          10.555,
+			AmountPrecision: 1,
+			Expected:        10.6212,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0, euroxref.WithAmountPrecision(test.AmountPrecision))
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		res, err := client.Convert(test.Amount, "EUR", "USD", time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if test.Expected != res {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.Expected, res, i)
+		}
+	}
+}
+
+func TestSourceURL(t *testing.T) {
+	tests := []struct {
+		SourceURL string
+		Path      string
+		Err       bool
+	}{
+		{
+			SourceURL: "http://mirror.local/rates/hist-90d.xml",
+			Path:      "/rates/hist-90d.xml",
+			Err:       false,
+		},
+		{
+			SourceURL: "",
+			Err:       true,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0, euroxref.WithSourceURL(test.SourceURL))
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		_, err := client.Fetch(time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC))
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			if reqUrl != "" {
+				t.Errorf("Want no request; got %s (i:%d)", reqUrl, i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !strings.HasSuffix(reqUrl, test.Path) {
+			t.Errorf("Want request to %s; got %s (i:%d)", test.Path, reqUrl, i)
+		}
+	}
+	for i, source := range []string{"", "mirror.local/rates.xml", "ftp://mirror.local/rates.xml"} {
+		if _, err := euroxref.NewValidated(4, 0, euroxref.WithSourceURL(source)); err == nil {
+			t.Errorf("Want err != nil for %q; got nil (i:%d)", source, i)
+		}
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	hc := &http.Client{Timeout: time.Minute}
+	tests := []struct {
+		Option   euroxref.Option
+		Expected *http.Client
+	}{
+		{
+			Option:   euroxref.WithHTTPClient(hc),
+			Expected: hc,
+		},
+		{
+			Option:   euroxref.WithHTTPClient(nil),
+			Expected: &http.Client{Timeout: euroxref.DefaultTimeout},
+		},
+	}
+	for i, test := range tests {
+		client := euroxref.New(4, 0, test.Option)
+		if !reflect.DeepEqual(client.(*euroxref.Client).HTTPClient, test.Expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", client.(*euroxref.Client).HTTPClient, test.Expected, i)
+		}
+	}
+	if client := euroxref.New(4, 0, euroxref.WithHTTPClient(nil)); client.(*euroxref.Client).HTTPClient == http.DefaultClient {
+		t.Errorf("Want client not to share http.DefaultClient")
+	}
+}
+
+func TestWithTransport(t *testing.T) {
+	var reqUrl, reqMethod, reqBody string
+	mockedServer := httptest.NewServer(testHandle(&reqUrl, &reqMethod, &reqBody))
+	defer mockedServer.Close()
+	transport := &MockedTransport{
+		Transport: http.Transport{
+			Proxy: func(req *http.Request) (*url.URL, error) {
+				return url.Parse(mockedServer.URL)
+			},
+		},
+	}
+	hc := &http.Client{Timeout: time.Minute}
+	client := euroxref.New(4, 0, euroxref.WithHTTPClient(hc), euroxref.WithTransport(transport))
+	res, err := client.Fetch(time.Date(2016, time.November, 9, 23, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Errorf("Want err == nil; got %v", err)
+	}
+	if len(res) != 1 || res[0].Rate != 2.999999 {
+		t.Errorf("Unexpected rates %v", res)
+	}
+	used := client.(*euroxref.Client).HTTPClient
+	if used == hc || used.Transport != transport || used.Timeout != time.Minute {
+		t.Errorf("Want copy of configured client using the transport; got %+v", used)
+	}
+	if hc.Transport != nil {
+		t.Errorf("Want configured client to be left intact; got %+v", hc)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	client := euroxref.New(4, 0)
+	if client.(*euroxref.Client).HTTPClient == http.DefaultClient {
+		t.Errorf("Want client not to share http.DefaultClient")
+	}
+	if timeout := client.(*euroxref.Client).HTTPClient.Timeout; timeout != euroxref.DefaultTimeout {
+		t.Errorf("Values `%v` and `%v` are not equal", timeout, euroxref.DefaultTimeout)
+	}
+
+	client = euroxref.New(4, 0, euroxref.WithTimeout(20*time.Millisecond))
+	done := make(chan struct{})
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+		}
+	})
+	defer mock.Close()
+	defer close(done)
+	_, err := client.Fetch(time.Date(2016, time.November, 9, 23, 0, 0, 0, time.UTC))
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("Want timeout error; got %v", err)
+	}
+}
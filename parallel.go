@@ -0,0 +1,69 @@
+package euroxref
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// WithParseWorkers sets number of goroutines parsing days of data for FetchRange, FetchAll and other methods
+// returning rates of multiple days, GOMAXPROCS by default. 1 parses days sequentially, values below 1 restore
+// the default. With more than one worker OnMalformed may be called from multiple goroutines at once.
+func WithParseWorkers(n int) Option {
+	return func(c *Client) {
+		c.parseWorkers = n
+	}
+}
+
+// parseWorkerCount returns number of goroutines used for parsing n records.
+func (c *Client) parseWorkerCount(n int) int {
+	workers := c.parseWorkers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+	return workers
+}
+
+// parseRecords parses records same as parseRecord, spreading the work across parse workers.
+// Results are in order of records, if any of them fails error of the first failed one is returned.
+// c.mu has to be held by the caller.
+func (c *Client) parseRecords(records []*XRefRawData) (results []ExchangeRates, err error) {
+	results = make([]ExchangeRates, len(records))
+	workers := c.parseWorkerCount(len(records))
+	if workers <= 1 {
+		for idx, record := range records {
+			results[idx], err = c.parseRecord(record)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return
+	}
+	errs := make([]error, len(records))
+	// Records are handed out one by one, as days can differ a lot in number of rates.
+	next := int64(-1)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				idx := int(atomic.AddInt64(&next, 1))
+				if idx >= len(records) {
+					return
+				}
+				results[idx], errs[idx] = c.parseRecord(records[idx])
+			}
+		}()
+	}
+	wg.Wait()
+	for _, err = range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return
+}
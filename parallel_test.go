@@ -0,0 +1,113 @@
+package euroxref_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/exaroth/euroxref-konrad"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// historyResponse returns data shaped like ECB full history feed, with given number of business days
+// and currencies, newest day first.
+func historyResponse(days, currencies int) *euroxref.XRefRawResponse {
+	data := &euroxref.XRefRawResponse{}
+	date := time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC)
+	for len(data.Data) < days {
+		if date.Weekday() != time.Saturday && date.Weekday() != time.Sunday {
+			dayD := euroxref.XRefRawData{RateTime: date.Format(euroxref.XRefDateLayout)}
+			for c := 0; c < currencies; c++ {
+				dayD.Rates = append(dayD.Rates, euroxref.RawExchangeRate{
+					Currency: fmt.Sprintf("C%02d", c),
+					Rate:     fmt.Sprintf("%d.%04d", c+1, (len(data.Data)*7+c)%10000),
+				})
+			}
+			data.Data = append(data.Data, dayD)
+		}
+		date = date.AddDate(0, 0, -1)
+	}
+	return data
+}
+
+func TestParseWorkers(t *testing.T) {
+	history := historyResponse(500, 30)
+	history.Data[321].Rates[5].Rate = "1,5"
+	history.Data[123].Rates[7].Rate = "2,5"
+	tests := []struct {
+		Data *euroxref.XRefRawResponse
+		Err  bool
+	}{
+		{
+			Data: historyResponse(500, 30),
+			Err:  false,
+		},
+		{
+			// Error of the first failed day in feed order is returned regardless of the number of workers.
+			Data: history,
+			Err:  true,
+		},
+	}
+	for i, test := range tests {
+		var expected map[time.Time]euroxref.ExchangeRates
+		var expectedErr error
+		for _, workers := range []int{1, 0, 4, 1000} {
+			client := euroxref.New(4, 60, euroxref.WithParseWorkers(workers))
+			mock := MockServer(t, client.(*euroxref.Client), xmlHandle(test.Data))
+			res, err := client.FetchAll()
+			mock.Close()
+			if test.Err {
+				var pErr *euroxref.ParseError
+				if !errors.As(err, &pErr) {
+					t.Errorf("Want *ParseError; got %v (i:%d)", err, i)
+					continue
+				}
+				if pErr.RateTime != test.Data.Data[123].RateTime {
+					t.Errorf("Values `%v` and `%v` are not equal (i:%d)", pErr.RateTime, test.Data.Data[123].RateTime, i)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			}
+			if workers == 1 {
+				expected, expectedErr = res, err
+				continue
+			}
+			if len(res) != len(test.Data.Data) || !reflect.DeepEqual(res, expected) || err != expectedErr {
+				t.Errorf("Results of %d workers differ from sequential ones (i:%d)", workers, i)
+			}
+		}
+	}
+}
+
+func benchmarkFetchAll(b *testing.B, workers int) {
+	var body bytes.Buffer
+	// Full history feed covers over 6500 days since 1999 with around 30 currencies each.
+	if err := xml.NewEncoder(&body).Encode(historyResponse(6600, 30)); err != nil {
+		b.Fatal(err)
+	}
+	client := euroxref.NewClient(4, 60, euroxref.WithParseWorkers(workers))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Data is loaded again so that parsed days aren't served from cache.
+		b.StopTimer()
+		if err := client.LoadFrom(bytes.NewReader(body.Bytes())); err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+		if _, err := client.FetchAll(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFetchAllSequential(b *testing.B) {
+	benchmarkFetchAll(b, 1)
+}
+
+func BenchmarkFetchAllParallel(b *testing.B) {
+	benchmarkFetchAll(b, 0)
+}
@@ -0,0 +1,160 @@
+package euroxref
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// RateJump describes change of currency rate between consecutive published days exceeding the threshold.
+type RateJump struct {
+	// Currency which rate changed.
+	Currency string
+	// Date of the prior available rate.
+	From time.Time
+	// Date of the rate which jumped.
+	To time.Time
+	// Prior available rate.
+	FromRate float64
+	// Rate after the jump.
+	ToRate float64
+	// Percentage change between rates.
+	Change float64
+}
+
+// WithJumpDetection makes client call fn for every currency which rate changes by more than
+// threshold percent compared to the prior available day, checked whenever data is retrieved.
+// Only days which weren't present in data held before are reported, so jumps aren't reported again on refresh.
+// ECB rates rarely move more than a few percent a day, large jumps usually indicate bad feed data.
+func WithJumpDetection(threshold float64, fn func(RateJump)) Option {
+	return func(c *Client) {
+		c.jumpThreshold = threshold
+		c.onJump = fn
+	}
+}
+
+// detectJumps returns suspicious rate changes found in data on days which aren't present in prev,
+// prev can be nil. Jumps are only collected as the callback can't be called while c.mu is held,
+// they're passed to it by reportJumps instead.
+func (c *Client) detectJumps(prev, data *XRefRawResponse) (jumps []RateJump) {
+	if c.onJump == nil {
+		return
+	}
+	known := make(map[string]bool)
+	if prev != nil {
+		for _, dayD := range prev.Data {
+			known[dayD.RateTime] = true
+		}
+	}
+	days := append([]XRefRawData{}, data.Data...)
+	// Dates are in XRefDateLayout so lexical order matches chronological one.
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].RateTime < days[j].RateTime
+	})
+	type lastRate struct {
+		date time.Time
+		rate float64
+	}
+	last := make(map[string]lastRate)
+	for _, dayD := range days {
+		date, err := time.Parse(XRefDateLayout, dayD.RateTime)
+		if err != nil {
+			continue
+		}
+		for _, rec := range dayD.Rates {
+			rate, err := strconv.ParseFloat(rec.Rate, 64)
+			if err != nil {
+				continue
+			}
+			if prior, ok := last[rec.Currency]; ok && prior.rate != 0 && !known[dayD.RateTime] {
+				change := (rate - prior.rate) / prior.rate * 100
+				if math.Abs(change) > c.jumpThreshold {
+					jumps = append(jumps, RateJump{
+						Currency: rec.Currency,
+						From:     prior.date,
+						To:       date,
+						FromRate: prior.rate,
+						ToRate:   rate,
+						Change:   c.round(change),
+					})
+				}
+			}
+			last[rec.Currency] = lastRate{date: date, rate: rate}
+		}
+	}
+	return
+}
+
+// reportJumps passes jumps collected by detectJumps to the jump detection callback,
+// c.mu mustn't be held by the caller so that the callback can use the client.
+func (c *Client) reportJumps(jumps []RateJump) {
+	for _, jump := range jumps {
+		c.onJump(jump)
+	}
+}
+
+// TriangulationIssue describes cycle of conversions between 3 currencies which doesn't return
+// the starting amount within tolerance.
+type TriangulationIssue struct {
+	// Currencies converted through in order, starting and ending with the first one.
+	Currencies [3]string
+	// Result of converting one unit of the first currency through the cycle.
+	RoundTrip float64
+	// Absolute difference between RoundTrip and one rounded to client precision.
+	Deviation float64
+}
+
+// VerifyTriangulation checks that rates published for given day are consistent under triangulation:
+// one unit of currency A converted to B, then to C and back to A (each step rounded same as Convert) should
+// differ from one by no more than tolerance. Every ordered triple of distinct currencies, including EUR,
+// is checked and the ones exceeding tolerance are returned, sorted by currencies.
+func (c *Client) VerifyTriangulation(t time.Time, tolerance float64) (issues []TriangulationIssue, err error) {
+	if tolerance < 0 || math.IsNaN(tolerance) {
+		return nil, errors.New("Tolerance of triangulation has to be a non-negative number")
+	}
+	dayData, err := c.Fetch(t)
+	if err != nil {
+		return
+	}
+	rates := withEUR(dayData)
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].Currency < rates[j].Currency
+	})
+	for i := range rates {
+		for j := range rates {
+			for k := range rates {
+				if i == j || j == k || i == k {
+					continue
+				}
+				var roundTrip float64
+				roundTrip, err = c.roundTrip(&rates[i], &rates[j], &rates[k])
+				if err != nil {
+					return nil, err
+				}
+				// Deviation is rounded so that floating point error of the subtraction isn't reported.
+				if deviation := c.round(math.Abs(roundTrip - 1)); deviation > tolerance {
+					issues = append(issues, TriangulationIssue{
+						Currencies: [3]string{rates[i].Currency, rates[j].Currency, rates[k].Currency},
+						RoundTrip:  roundTrip,
+						Deviation:  deviation,
+					})
+				}
+			}
+		}
+	}
+	return
+}
+
+// roundTrip converts one unit of first currency to second, then third and back to first.
+func (c *Client) roundTrip(first, second, third *ExchangeRate) (result float64, err error) {
+	result = 1
+	for _, step := range [][2]*ExchangeRate{{first, second}, {second, third}, {third, first}} {
+		result, err = c.computeExchangeValue(result, step[0], step[1])
+		if err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,215 @@
+package euroxref_test
+
+import (
+	"context"
+	"github.com/exaroth/euroxref-konrad"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJumpDetection(t *testing.T) {
+	tests := []struct {
+		Threshold float64
+		Expected  []euroxref.RateJump
+	}{
+		{
+			Threshold: 50,
+			Expected: []euroxref.RateJump{
+				{
+					Currency: "USD",
+					From:     time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+					To:       time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+					FromRate: 2.999999,
+					ToRate:   1.003123142,
+					Change:   -66.5626,
+				},
+			},
+		},
+		{
+			Threshold: 0.01,
+			Expected: []euroxref.RateJump{
+				{
+					Currency: "USD",
+					From:     time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+					To:       time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+					FromRate: 2.999999,
+					ToRate:   1.003123142,
+					Change:   -66.5626,
+				},
+				{
+					Currency: "USD",
+					From:     time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+					To:       time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+					FromRate: 1.003123142,
+					ToRate:   1.002,
+					Change:   -0.112,
+				},
+				{
+					Currency: "PLN",
+					From:     time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+					To:       time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+					FromRate: 0.3211231231,
+					ToRate:   0.321,
+					Change:   -0.0383,
+				},
+			},
+		},
+		{
+			Threshold: 100,
+			Expected:  nil,
+		},
+	}
+	for i, test := range tests {
+		var jumps []euroxref.RateJump
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0, euroxref.WithJumpDetection(test.Threshold, func(jump euroxref.RateJump) {
+			jumps = append(jumps, jump)
+		}))
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		if _, err := client.Fetch(time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)); err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(test.Expected, jumps) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.Expected, jumps, i)
+		}
+	}
+}
+
+func TestJumpDetectionRefresh(t *testing.T) {
+	response := &euroxref.XRefRawResponse{
+		Data: []euroxref.XRefRawData{
+			{
+				RateTime: "2016-11-10",
+				Rates:    []euroxref.RawExchangeRate{{Currency: "USD", Rate: "1"}},
+			},
+			{
+				RateTime: "2016-11-11",
+				Rates:    []euroxref.RawExchangeRate{{Currency: "USD", Rate: "2"}},
+			},
+		},
+	}
+	var jumps []time.Time
+	var client *euroxref.Client
+	// Callback uses the client, which would deadlock if it was called while data of the client is locked.
+	client = euroxref.NewClient(4, 0, euroxref.WithJumpDetection(10, func(jump euroxref.RateJump) {
+		client.LastFetched()
+		jumps = append(jumps, jump.To)
+	}))
+	mock := MockServer(t, client, xmlHandle(response))
+	defer mock.Close()
+	if _, err := client.Fetch(time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Errorf("Want err == nil; got %v (i:%d)", err, 0)
+	}
+	response.Data = append(response.Data, euroxref.XRefRawData{
+		RateTime: "2016-11-14",
+		Rates:    []euroxref.RawExchangeRate{{Currency: "USD", Rate: "3"}},
+	})
+	// Refreshed data contains all days retrieved before, only jump on the new day is reported.
+	if err := client.Refresh(context.Background()); err != nil {
+		t.Errorf("Want err == nil; got %v (i:%d)", err, 1)
+	}
+	expected := []time.Time{
+		time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+		time.Date(2016, time.November, 14, 0, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(expected, jumps) {
+		t.Errorf("Values `%v` and `%v` are not equal (i:%d)", expected, jumps, 0)
+	}
+}
+
+func TestVerifyTriangulation(t *testing.T) {
+	response := &euroxref.XRefRawResponse{
+		Data: []euroxref.XRefRawData{
+			{
+				RateTime: "2016-11-11",
+				Rates: []euroxref.RawExchangeRate{
+					{
+						Currency: "USD",
+						Rate:     "3",
+					},
+					{
+						Currency: "GBP",
+						Rate:     "4",
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		Precision uint
+		Tolerance float64
+		Date      time.Time
+		Expected  []euroxref.TriangulationIssue
+		Err       bool
+	}{
+		{
+			Precision: 1,
+			Tolerance: 0,
+			Date:      time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Expected: []euroxref.TriangulationIssue{
+				{Currencies: [3]string{"GBP", "EUR", "USD"}, RoundTrip: 1.2, Deviation: 0.2},
+				{Currencies: [3]string{"GBP", "USD", "EUR"}, RoundTrip: 1.2, Deviation: 0.2},
+				{Currencies: [3]string{"USD", "EUR", "GBP"}, RoundTrip: 0.9, Deviation: 0.1},
+				{Currencies: [3]string{"USD", "GBP", "EUR"}, RoundTrip: 0.9, Deviation: 0.1},
+			},
+		},
+		{
+			Precision: 1,
+			Tolerance: 0.1,
+			Date:      time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Expected: []euroxref.TriangulationIssue{
+				{Currencies: [3]string{"GBP", "EUR", "USD"}, RoundTrip: 1.2, Deviation: 0.2},
+				{Currencies: [3]string{"GBP", "USD", "EUR"}, RoundTrip: 1.2, Deviation: 0.2},
+			},
+		},
+		{
+			// Amounts are rounded to cents before every step.
+			Precision: 4,
+			Tolerance: 0,
+			Date:      time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Expected: []euroxref.TriangulationIssue{
+				{Currencies: [3]string{"USD", "EUR", "GBP"}, RoundTrip: 0.99, Deviation: 0.01},
+				{Currencies: [3]string{"USD", "GBP", "EUR"}, RoundTrip: 0.99, Deviation: 0.01},
+			},
+		},
+		{
+			Precision: 4,
+			Tolerance: 0.01,
+			Date:      time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Expected:  nil,
+		},
+		{
+			Precision: 4,
+			Tolerance: -1,
+			Date:      time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+			Err:       true,
+		},
+		{
+			Precision: 4,
+			Tolerance: 0,
+			Date:      time.Date(2016, time.November, 12, 0, 0, 0, 0, time.UTC),
+			Err:       true,
+		},
+	}
+	for i, test := range tests {
+		client := euroxref.New(test.Precision, 60)
+		mock := MockServer(t, client.(*euroxref.Client), xmlHandle(response))
+		issues, err := client.VerifyTriangulation(test.Date, test.Tolerance)
+		mock.Close()
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(test.Expected, issues) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.Expected, issues, i)
+		}
+	}
+}
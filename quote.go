@@ -0,0 +1,289 @@
+package euroxref
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+)
+
+// bpsDenominator is the number of basis points in a whole.
+const bpsDenominator = 10000
+
+// splitWeightTolerance is the accepted difference between sum of ConvertSplit weights and one.
+const splitWeightTolerance = 1e-6
+
+// spreadRat returns factor by which rate is reduced by spread given in basis points.
+func spreadRat(spreadBps int) (*big.Rat, error) {
+	if spreadBps < 0 || spreadBps >= bpsDenominator {
+		return nil, errors.New(fmt.Sprintf("Invalid spread: %d bps, spread has to be between 0 and %d", spreadBps, bpsDenominator-1))
+	}
+	return big.NewRat(int64(bpsDenominator-spreadBps), bpsDenominator), nil
+}
+
+// SpreadSide determines direction in which ConvertWithSpread moves the exchange rate.
+type SpreadSide int
+
+const (
+	// SpreadBid lowers the rate by the spread, so less of target currency is received. It's the default.
+	SpreadBid SpreadSide = iota
+	// SpreadAsk raises the rate by the spread, so more of target currency is charged.
+	SpreadAsk
+)
+
+// WithSpreadSide sets direction in which ConvertWithSpread moves the exchange rate, SpreadBid by default.
+func WithSpreadSide(side SpreadSide) Option {
+	return func(c *Client) {
+		c.spreadSide = side
+	}
+}
+
+// QuoteSource computes amount of source currency required to receive targetAmount of target
+// currency after applying spread (in basis points) to the exchange rate.
+// Amount is computed using the exact rate and rounded up to amount precision, so that converting it
+// with the same rate yields at least targetAmount. appliedRate is the marked-up rate used
+// for the computation, rounded to client precision.
+func (c *Client) QuoteSource(targetAmount float64, source, target string, t time.Time, spreadBps int) (sourceAmount, appliedRate float64, err error) {
+	if targetAmount < 0 {
+		return sourceAmount, appliedRate, errors.New("Amount of target currency can't be negative")
+	}
+	x, ok := ratFromFloat(targetAmount)
+	if !ok {
+		return sourceAmount, appliedRate, errors.New("Amount of target currency has to be a finite number")
+	}
+	factor, err := spreadRat(spreadBps)
+	if err != nil {
+		return
+	}
+	var dayData ExchangeRates
+	var in, to *ExchangeRate
+	codes, err := c.checkCurrencies(source, target)
+	if err != nil {
+		return
+	}
+	source, target = codes[0], codes[1]
+	dayData, err = c.Fetch(t)
+	if err != nil {
+		return
+	}
+	in, to, err = lookupPair(dayData, source, target, t)
+	if err != nil {
+		return
+	}
+	rate, err := crossRateRat(in, to)
+	if err != nil {
+		return
+	}
+	rate.Mul(rate, factor)
+	if rate.Sign() == 0 {
+		return 0, 0, errors.New(fmt.Sprintf("Exchange rate between %s and %s is zero", source, target))
+	}
+	appliedRate = ratToFixed(rate, c.prec, c.roundingMode)
+	sourceAmount, _ = roundRat(x.Quo(x, rate), c.amountPrecision(), Ceil).Float64()
+	return
+}
+
+// ConvertWatchlist converts amount of source currency into each of target currencies same as Convert,
+// applying spread (in basis points) given for each of them to the exact exchange rate.
+// Exchange rates are fetched once, results for valid targets are returned keyed by upper case codes even if
+// some of them failed, in which case *TargetsError describing failed targets is returned as well.
+func (c *Client) ConvertWatchlist(amount float64, source string, targets map[string]int, t time.Time) (results map[string]float64, err error) {
+	if amount < 0 && !c.AllowNegativeAmounts {
+		return results, errors.New("Amount of conversion currency can't be negative")
+	}
+	x, ok := ratFromFloat(amount)
+	if !ok {
+		return results, errors.New("Amount of conversion currency has to be a finite number")
+	}
+	codes, err := c.checkCurrencies(source)
+	if err != nil {
+		return
+	}
+	dayData, err := c.Fetch(t)
+	if err != nil {
+		return
+	}
+	in, _, err := lookupPair(dayData, codes[0], codes[0], t)
+	if err != nil {
+		return
+	}
+	results = make(map[string]float64)
+	failed := make(map[string]error)
+	for target, spreadBps := range targets {
+		to, lookupErr := c.splitTarget(dayData, target, t)
+		if lookupErr != nil {
+			failed[target] = lookupErr
+			continue
+		}
+		factor, spreadErr := spreadRat(spreadBps)
+		if spreadErr != nil {
+			failed[target] = spreadErr
+			continue
+		}
+		rate, rateErr := crossRateRat(in, to)
+		if rateErr != nil {
+			failed[target] = rateErr
+			continue
+		}
+		results[to.Currency], _ = c.applyRateRat(x, rate.Mul(rate, factor), c.resultParams(to.Currency)...).Float64()
+	}
+	if len(failed) > 0 {
+		err = &TargetsError{Targets: failed}
+	}
+	return
+}
+
+// ConvertSplit splits amount of source currency between target currencies by their weights, which have to be
+// non-negative and sum to one, and converts each part into its target, e.g. weights of 0.6 and 0.4 convert
+// 60% and 40% of the amount. Exchange rates are fetched once. Results are keyed by target currencies as given,
+// if any of them can't be converted no results are returned and *TargetsError describes the failed ones.
+func (c *Client) ConvertSplit(amount float64, source string, targets map[string]float64, t time.Time) (results map[string]float64, err error) {
+	if len(targets) == 0 {
+		return nil, errors.New("No target currencies given")
+	}
+	var sum float64
+	for target, weight := range targets {
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, errors.New(fmt.Sprintf("Invalid weight of %s: %v, weights have to be non-negative", target, weight))
+		}
+		sum += weight
+	}
+	if math.Abs(sum-1) > splitWeightTolerance {
+		return nil, errors.New(fmt.Sprintf("Weights of target currencies sum to %v instead of 1", sum))
+	}
+	if amount < 0 && !c.AllowNegativeAmounts {
+		return nil, errors.New("Amount of conversion currency can't be negative")
+	}
+	x, ok := ratFromFloat(amount)
+	if !ok {
+		return nil, errors.New("Amount of conversion currency has to be a finite number")
+	}
+	codes, err := c.checkCurrencies(source)
+	if err != nil {
+		return
+	}
+	dayData, err := c.Fetch(t)
+	if err != nil {
+		return
+	}
+	in, _, err := lookupPair(dayData, codes[0], codes[0], t)
+	if err != nil {
+		return
+	}
+	results = make(map[string]float64, len(targets))
+	failed := make(map[string]error)
+	for target, weight := range targets {
+		to, lookupErr := c.splitTarget(dayData, target, t)
+		if lookupErr != nil {
+			failed[target] = lookupErr
+			continue
+		}
+		w, _ := ratFromFloat(weight)
+		part, _ := new(big.Rat).Mul(x, w).Float64()
+		results[target], err = c.computeExchangeValue(part, in, to, c.resultParams(to.Currency)...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(failed) > 0 {
+		return nil, &TargetsError{Targets: failed}
+	}
+	return
+}
+
+// splitTarget returns exchange rate of target currency in dayData, validating its code first.
+func (c *Client) splitTarget(dayData ExchangeRates, target string, t time.Time) (to *ExchangeRate, err error) {
+	codes, err := c.checkCurrencies(target)
+	if err != nil {
+		return
+	}
+	_, to, err = lookupPair(dayData, codes[0], codes[0], t)
+	return
+}
+
+// ConvertVia converts amount of source currency into target currency routing the conversion through
+// pivot currency, i.e. source is converted to pivot and the result of that to target, as some brokers
+// quote cross rates. Intermediate amount is rounded to client precision, so the result can slightly
+// differ from the one returned by Convert, which matters when reconciling with such quotes.
+func (c *Client) ConvertVia(amount float64, source, target, pivot string, t time.Time) (result float64, err error) {
+	if amount < 0 && !c.AllowNegativeAmounts {
+		return result, errors.New("Amount of conversion currency can't be negative")
+	}
+	x, ok := ratFromFloat(amount)
+	if !ok {
+		return result, errors.New("Amount of conversion currency has to be a finite number")
+	}
+	codes, err := c.checkCurrencies(source, target, pivot)
+	if err != nil {
+		return
+	}
+	dayData, err := c.Fetch(t)
+	if err != nil {
+		return
+	}
+	in, pv, err := lookupPair(dayData, codes[0], codes[2], t)
+	if err != nil {
+		return
+	}
+	_, to, err := lookupPair(dayData, codes[2], codes[1], t)
+	if err != nil {
+		return
+	}
+	first, err := crossRateRat(in, pv)
+	if err != nil {
+		return
+	}
+	second, err := crossRateRat(pv, to)
+	if err != nil {
+		return
+	}
+	x = roundRat(x, c.amountPrecision(), c.roundingMode)
+	leg := roundRat(x.Mul(x, first), c.resultPrecision(), c.roundingMode)
+	result, _ = c.roundResultRat(leg.Mul(leg, second), c.resultParams(codes[1])...).Float64()
+	return
+}
+
+// ConvertWithSpread converts amount same as Convert, with exchange rate moved by spreadPct percent
+// in direction set by WithSpreadSide, e.g. 2% spread turns rate of 1.5 into 1.47 on bid side and 1.53 on ask one.
+// Spread is applied to the exact rate and the result is rounded only once, spread of 0 matches Convert.
+func (c *Client) ConvertWithSpread(amount float64, source, target string, t time.Time, spreadPct float64) (result float64, err error) {
+	if spreadPct < 0 || spreadPct >= 100 || math.IsNaN(spreadPct) {
+		return result, errors.New(fmt.Sprintf("Invalid spread: %v%%, spread has to be at least 0 and less than 100", spreadPct))
+	}
+	if spreadPct == 0 {
+		return c.Convert(amount, source, target, t)
+	}
+	if amount < 0 && !c.AllowNegativeAmounts {
+		return result, errors.New("Amount of conversion currency can't be negative")
+	}
+	x, ok := ratFromFloat(amount)
+	if !ok {
+		return result, errors.New("Amount of conversion currency has to be a finite number")
+	}
+	codes, err := c.checkCurrencies(source, target)
+	if err != nil {
+		return
+	}
+	dayData, err := c.Fetch(t)
+	if err != nil {
+		return
+	}
+	in, to, err := lookupPair(dayData, codes[0], codes[1], t)
+	if err != nil {
+		return
+	}
+	rate, err := crossRateRat(in, to)
+	if err != nil {
+		return
+	}
+	spread, _ := ratFromFloat(spreadPct)
+	spread.Quo(spread, big.NewRat(100, 1))
+	if c.spreadSide == SpreadAsk {
+		spread.Add(big.NewRat(1, 1), spread)
+	} else {
+		spread.Sub(big.NewRat(1, 1), spread)
+	}
+	result, _ = c.applyRateRat(x, rate.Mul(rate, spread), c.resultParams(codes[1])...).Float64()
+	return
+}
@@ -0,0 +1,530 @@
+package euroxref_test
+
+import (
+	"errors"
+	"github.com/exaroth/euroxref-konrad"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestQuoteSource(t *testing.T) {
+	tests := []struct {
+		Date         time.Time
+		Amount       float64
+		Currencies   [2]string
+		SpreadBps    int
+		ExpectedRate float64
+		Expected     float64
+		Err          bool
+	}{
+		{
+			Date:         time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount:       97.28,
+			Currencies:   [2]string{"CHF", "USD"},
+			SpreadBps:    0,
+			ExpectedRate: 0.9728,
+			Expected:     100,
+			Err:          false,
+		},
+		{
+			Date:         time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount:       100,
+			Currencies:   [2]string{"CHF", "USD"},
+			SpreadBps:    100,
+			ExpectedRate: 0.9631,
+			Expected:     103.84,
+			Err:          false,
+		},
+		{
+			Date:         time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount:       100,
+			Currencies:   [2]string{"EUR", "USD"},
+			SpreadBps:    50,
+			ExpectedRate: 0.997,
+			Expected:     100.31,
+			Err:          false,
+		},
+		{
+			Date:       time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount:     100,
+			Currencies: [2]string{"CHF", "USD"},
+			SpreadBps:  -1,
+			Err:        true,
+		},
+		{
+			Date:       time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount:     -100,
+			Currencies: [2]string{"CHF", "USD"},
+			Err:        true,
+		},
+		{
+			Date:       time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount:     100,
+			Currencies: [2]string{"CHF", "BLE"},
+			Err:        true,
+		},
+		{
+			Date:       time.Date(2016, time.November, 8, 23, 0, 0, 0, time.UTC),
+			Amount:     100,
+			Currencies: [2]string{"CHF", "USD"},
+			Err:        true,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0)
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		res, rate, err := client.QuoteSource(test.Amount, test.Currencies[0], test.Currencies[1], test.Date, test.SpreadBps)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			}
+			if test.ExpectedRate != rate {
+				t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.ExpectedRate, rate, i)
+			}
+			if test.Expected != res {
+				t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.Expected, res, i)
+			}
+		}
+	}
+}
+
+func TestQuoteSourceCoversTarget(t *testing.T) {
+	pairs := [][2]string{{"EUR", "USD"}, {"CHF", "USD"}, {"USD", "XYZ"}, {"PLN", "CHF"}}
+	amounts := []float64{0.01, 97.28, 100, 1000, 12345.67}
+	var reqUrl, reqMethod, reqBody string
+	handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), handler)
+	defer mock.Close()
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	for i, pair := range pairs {
+		for _, amount := range amounts {
+			quote, _, err := client.QuoteSource(amount, pair[0], pair[1], date, 0)
+			if err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+				continue
+			}
+			res, err := client.Convert(quote, pair[0], pair[1], date)
+			if err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			}
+			if res < amount {
+				t.Errorf("Want converted quote %v of %s to cover %v %s; got %v (i:%d)", quote, pair[0], amount, pair[1], res, i)
+			}
+		}
+	}
+}
+
+func TestConvertWatchlist(t *testing.T) {
+	tests := []struct {
+		Date     time.Time
+		Amount   float64
+		Source   string
+		Targets  map[string]int
+		Decimals bool
+		Strict   bool
+		Expected map[string]float64
+		Failed   []string
+		Err      bool
+	}{
+		{
+			Date:   time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount: 10,
+			Source: "CHF",
+			Targets: map[string]int{
+				"USD": 0,
+				"EUR": 100,
+				"CHF": 0,
+			},
+			Expected: map[string]float64{
+				"USD": 9.7282,
+				"EUR": 9.6117,
+				"CHF": 10,
+			},
+			Err: false,
+		},
+		{
+			Date:   time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount: 10,
+			Source: "chf",
+			Targets: map[string]int{
+				"usd": 0,
+				"jpy": 0,
+			},
+			Decimals: true,
+			Strict:   true,
+			Expected: map[string]float64{
+				"USD": 9.73,
+			},
+			Failed: []string{"jpy"},
+			Err:    true,
+		},
+		{
+			Date:    time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount:  10,
+			Source:  "CHF",
+			Targets: map[string]int{"ABC": 0},
+			Strict:  true,
+			Failed:  []string{"ABC"},
+			Err:     true,
+		},
+		{
+			Date:    time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount:  math.NaN(),
+			Source:  "CHF",
+			Targets: map[string]int{"USD": 0},
+			Err:     true,
+		},
+		{
+			Date:   time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount: 10,
+			Source: "EUR",
+			Targets: map[string]int{
+				"USD": 50,
+				"BLE": 0,
+				"PLN": -10,
+			},
+			Expected: map[string]float64{
+				"USD": 9.9699,
+			},
+			Failed: []string{"BLE", "PLN"},
+			Err:    true,
+		},
+		{
+			Date:    time.Date(2016, time.November, 11, 23, 0, 0, 0, time.UTC),
+			Amount:  10,
+			Source:  "BLE",
+			Targets: map[string]int{"USD": 0},
+			Err:     true,
+		},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0)
+		client.(*euroxref.Client).UseCurrencyDecimals = test.Decimals
+		client.(*euroxref.Client).StrictCurrencies = test.Strict
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		res, err := client.ConvertWatchlist(test.Amount, test.Source, test.Targets, test.Date)
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+		} else if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if len(test.Failed) > 0 {
+			var tErr *euroxref.TargetsError
+			if !errors.As(err, &tErr) {
+				t.Errorf("Want *TargetsError; got %v (i:%d)", err, i)
+				continue
+			}
+			for _, target := range test.Failed {
+				if _, ok := tErr.Targets[target]; !ok {
+					t.Errorf("Want %s to be reported as failed (i:%d)", target, i)
+				}
+			}
+			var nfErr *euroxref.ErrCurrencyNotFound
+			if targetErr, ok := tErr.Targets["BLE"]; ok && !errors.As(targetErr, &nfErr) {
+				t.Errorf("Want *ErrCurrencyNotFound; got %v (i:%d)", targetErr, i)
+			}
+		}
+		if test.Expected != nil && !reflect.DeepEqual(test.Expected, res) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.Expected, res, i)
+		}
+	}
+}
+
+func TestConvertVia(t *testing.T) {
+	tests := []struct {
+		Amount     float64
+		Currencies [3]string
+		Expected   float64
+		Direct     float64
+		Err        bool
+	}{
+		{
+			Amount:     10,
+			Currencies: [3]string{"PLN", "XYZ", "USD"},
+			Expected:   62.3054,
+			Direct:     62.3053,
+			Err:        false,
+		},
+		{
+			Amount:     10,
+			Currencies: [3]string{"CHF", "PLN", "EUR"},
+			Expected:   3.1165,
+			Direct:     3.1165,
+			Err:        false,
+		},
+		{
+			Amount:     10,
+			Currencies: [3]string{"usd", "pln", "xyz"},
+			Expected:   3.2036,
+			Direct:     3.2036,
+			Err:        false,
+		},
+		{
+			Amount:     10,
+			Currencies: [3]string{"USD", "XYZ", "XYZ"},
+			Expected:   19.9601,
+			Direct:     19.9601,
+			Err:        false,
+		},
+		{
+			Amount:     10,
+			Currencies: [3]string{"USD", "PLN", "ABC"},
+			Err:        true,
+		},
+		{
+			Amount:     -10,
+			Currencies: [3]string{"USD", "PLN", "CHF"},
+			Err:        true,
+		},
+	}
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 60)
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		res, err := client.ConvertVia(test.Amount, test.Currencies[0], test.Currencies[1], test.Currencies[2], date)
+		if test.Err {
+			mock.Close()
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if res != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+		direct, err := client.Convert(test.Amount, test.Currencies[0], test.Currencies[1], date)
+		mock.Close()
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if direct != test.Direct {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", direct, test.Direct, i)
+		}
+	}
+}
+
+func TestConvertWithSpread(t *testing.T) {
+	tests := []struct {
+		Amount   float64
+		Source   string
+		Target   string
+		Spread   float64
+		Side     euroxref.SpreadSide
+		Expected float64
+		Err      bool
+	}{
+		{
+			Amount:   100,
+			Source:   "EUR",
+			Target:   "USD",
+			Spread:   2,
+			Side:     euroxref.SpreadBid,
+			Expected: 98.196,
+			Err:      false,
+		},
+		{
+			Amount:   100,
+			Source:   "EUR",
+			Target:   "USD",
+			Spread:   2,
+			Side:     euroxref.SpreadAsk,
+			Expected: 102.204,
+			Err:      false,
+		},
+		{
+			Amount:   10,
+			Source:   "usd",
+			Target:   "chf",
+			Spread:   1,
+			Side:     euroxref.SpreadBid,
+			Expected: 10.1766,
+			Err:      false,
+		},
+		{
+			Amount:   10,
+			Source:   "USD",
+			Target:   "CHF",
+			Spread:   0,
+			Side:     euroxref.SpreadAsk,
+			Expected: 10.2794,
+			Err:      false,
+		},
+		{
+			Amount: 10,
+			Source: "USD",
+			Target: "CHF",
+			Spread: -1,
+			Err:    true,
+		},
+		{
+			Amount: 10,
+			Source: "USD",
+			Target: "CHF",
+			Spread: 100,
+			Err:    true,
+		},
+		{
+			Amount: 10,
+			Source: "USD",
+			Target: "CHF",
+			Spread: math.NaN(),
+			Err:    true,
+		},
+		{
+			Amount: -10,
+			Source: "USD",
+			Target: "CHF",
+			Spread: 1,
+			Err:    true,
+		},
+		{
+			Amount: 10,
+			Source: "USD",
+			Target: "ABC",
+			Spread: 1,
+			Err:    true,
+		},
+	}
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 60, euroxref.WithSpreadSide(test.Side))
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		res, err := client.ConvertWithSpread(test.Amount, test.Source, test.Target, date, test.Spread)
+		mock.Close()
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if res != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+}
+
+func TestConvertSplit(t *testing.T) {
+	tests := []struct {
+		Amount   float64
+		Source   string
+		Targets  map[string]float64
+		Expected map[string]float64
+		Failed   []string
+		Err      bool
+	}{
+		{
+			Amount:   100,
+			Source:   "EUR",
+			Targets:  map[string]float64{"USD": 0.6, "PLN": 0.4},
+			Expected: map[string]float64{"USD": 60.12, "PLN": 12.84},
+			Err:      false,
+		},
+		{
+			Amount:   100,
+			Source:   "usd",
+			Targets:  map[string]float64{"eur": 0.5, "chf": 0.5},
+			Expected: map[string]float64{"eur": 49.9002, "chf": 51.3972},
+			Err:      false,
+		},
+		{
+			Amount:   100,
+			Source:   "EUR",
+			Targets:  map[string]float64{"USD": 1.0 / 3, "PLN": 1.0 / 3, "CHF": 1.0 / 3},
+			Expected: map[string]float64{"USD": 33.3967, "PLN": 10.6989, "CHF": 34.3299},
+			Err:      false,
+		},
+		{
+			Amount:  100,
+			Source:  "EUR",
+			Targets: map[string]float64{"USD": 0.5, "PLN": 0.4},
+			Err:     true,
+		},
+		{
+			Amount:  100,
+			Source:  "EUR",
+			Targets: map[string]float64{"USD": 1.5, "PLN": -0.5},
+			Err:     true,
+		},
+		{
+			Amount:  100,
+			Source:  "EUR",
+			Targets: map[string]float64{},
+			Err:     true,
+		},
+		{
+			Amount:  -100,
+			Source:  "EUR",
+			Targets: map[string]float64{"USD": 1},
+			Err:     true,
+		},
+		{
+			Amount:  100,
+			Source:  "EUR",
+			Targets: map[string]float64{"USD": 0.5, "ABC": 0.25, "DEF": 0.25},
+			Failed:  []string{"ABC", "DEF"},
+			Err:     true,
+		},
+	}
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 60)
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		res, err := client.ConvertSplit(test.Amount, test.Source, test.Targets, date)
+		mock.Close()
+		if test.Err {
+			if err == nil {
+				t.Errorf("Want err != nil; got nil (i:%d)", i)
+			}
+			if res != nil {
+				t.Errorf("Want no results; got %v (i:%d)", res, i)
+			}
+			if test.Failed == nil {
+				continue
+			}
+			var tErr *euroxref.TargetsError
+			if !errors.As(err, &tErr) {
+				t.Errorf("Want *TargetsError; got %v (i:%d)", err, i)
+				continue
+			}
+			for _, target := range test.Failed {
+				if _, ok := tErr.Targets[target]; !ok {
+					t.Errorf("Want %s to be reported as failed; got %v (i:%d)", target, tErr, i)
+				}
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if !reflect.DeepEqual(res, test.Expected) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package euroxref
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithRateLimit limits requests for exchange rate data sent by the client (including retries and requests
+// for other feeds) to rps per second on average, allowing bursts of up to burst requests.
+// Requests over the limit wait until they're allowed or their context is done. Non-positive rps disables it.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		if rps <= 0 {
+			c.limiter = nil
+			return
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		c.limiter = &rateLimiter{rate: rps, burst: float64(burst), tokens: float64(burst)}
+	}
+}
+
+// rateLimiter is a token bucket refilled at rate tokens per second up to burst tokens.
+type rateLimiter struct {
+	// Guards tokens and last.
+	mu sync.Mutex
+	// Number of tokens added per second.
+	rate float64
+	// Maximum number of tokens.
+	burst float64
+	// Tokens available as of last, negative if they're reserved by waiting callers.
+	tokens float64
+	// Time when tokens were last updated.
+	last time.Time
+}
+
+// wait blocks until request is allowed or ctx is done, in which case ctx.Err() is returned.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve takes a token and returns time after which it becomes available.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel returns token taken by reserve which wasn't used.
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
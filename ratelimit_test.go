@@ -0,0 +1,65 @@
+package euroxref_test
+
+import (
+	"context"
+	"errors"
+	"github.com/exaroth/euroxref-konrad"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	requests := 0
+	client := euroxref.New(4, 0, euroxref.WithRateLimit(20, 2))
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		xmlHandle(testResponse)(w, req)
+	})
+	defer mock.Close()
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err := client.Fetch(date); err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+	}
+	// Burst of 2 requests is allowed right away, remaining ones wait 50ms each.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Want requests to be throttled; took %v", elapsed)
+	}
+	if requests != 4 {
+		t.Errorf("Want 4 requests; got %d", requests)
+	}
+	// Waiting for the limiter is aborted along with the context.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.FetchContext(ctx, date); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Want context.DeadlineExceeded; got %v", err)
+	}
+	if requests != 4 {
+		t.Errorf("Want 4 requests; got %d", requests)
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	requests := 0
+	client := euroxref.New(4, 0, euroxref.WithRateLimit(0, 1))
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		xmlHandle(testResponse)(w, req)
+	})
+	defer mock.Close()
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := client.Fetch(time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)); err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Want requests not to be throttled; took %v", elapsed)
+	}
+	if requests != 5 {
+		t.Errorf("Want 5 requests; got %d", requests)
+	}
+}
@@ -0,0 +1,57 @@
+package euroxref
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/url"
+	"time"
+)
+
+// DefaultRetryBackoff is delay before first retry used when RetryBackoff isn't set.
+const DefaultRetryBackoff = 500 * time.Millisecond
+
+// fetchFeedRetry retrieves feed same as fetchFeed, retrying on network errors and 5xx responses
+// up to MaxRetries times with exponential backoff and jitter.
+func (c *Client) fetchFeedRetry(ctx context.Context, url string, validators *feedValidators) (data *XRefRawResponse, err error) {
+	for attempt := 0; ; attempt++ {
+		data, err = c.fetchFeed(ctx, url, validators)
+		if err == nil || attempt >= c.MaxRetries || !retryable(ctx, err) {
+			return
+		}
+		timer := time.NewTimer(c.retryDelay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryDelay returns delay before retry following given attempt, doubling with every attempt
+// and randomized by up to half of its value so that clients don't retry in lockstep.
+func (c *Client) retryDelay(attempt int) time.Duration {
+	backoff := c.RetryBackoff
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+	delay := backoff << uint(attempt)
+	if delay <= 0 {
+		delay = backoff
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay)/2+1))
+}
+
+// retryable checks if retrieval of the data which failed with err should be attempted again.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var sErr *StatusError
+	if errors.As(err, &sErr) {
+		return sErr.StatusCode >= 500
+	}
+	var uErr *url.Error
+	return errors.As(err, &uErr)
+}
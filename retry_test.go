@@ -0,0 +1,171 @@
+package euroxref_test
+
+import (
+	"encoding/xml"
+	"errors"
+	"github.com/exaroth/euroxref-konrad"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		Statuses   []int
+		MaxRetries int
+		Requests   int
+		Err        bool
+	}{
+		{
+			Statuses:   []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			MaxRetries: 2,
+			Requests:   3,
+			Err:        false,
+		},
+		{
+			Statuses:   []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			MaxRetries: 1,
+			Requests:   2,
+			Err:        true,
+		},
+		{
+			Statuses:   []int{http.StatusNotFound},
+			MaxRetries: 2,
+			Requests:   1,
+			Err:        true,
+		},
+		{
+			Statuses:   []int{},
+			MaxRetries: 0,
+			Requests:   1,
+			Err:        false,
+		},
+	}
+	for i, test := range tests {
+		requests := 0
+		client := euroxref.New(4, 0)
+		client.(*euroxref.Client).MaxRetries = test.MaxRetries
+		client.(*euroxref.Client).RetryBackoff = time.Millisecond
+		mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+			requests++
+			if requests <= len(test.Statuses) {
+				http.Error(w, http.StatusText(test.Statuses[requests-1]), test.Statuses[requests-1])
+				return
+			}
+			xmlHandle(testResponse)(w, req)
+		})
+		_, err := client.Fetch(time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC))
+		mock.Close()
+		if test.Err {
+			var sErr *euroxref.StatusError
+			if !errors.As(err, &sErr) {
+				t.Errorf("Want *StatusError; got %v (i:%d)", err, i)
+			}
+		} else if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if requests != test.Requests {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", requests, test.Requests, i)
+		}
+	}
+}
+
+func TestServeStale(t *testing.T) {
+	for i, serveStale := range []bool{false, true} {
+		requests := 0
+		client := euroxref.New(4, 0)
+		client.(*euroxref.Client).ServeStale = serveStale
+		mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+			requests++
+			if requests > 1 {
+				http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+				return
+			}
+			xmlHandle(testResponse)(w, req)
+		})
+		date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+		if _, err := client.Fetch(date); err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		res, err := client.Fetch(date)
+		mock.Close()
+		if serveStale {
+			if err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			}
+			if len(res) == 0 {
+				t.Errorf("Want stale rates; got none (i:%d)", i)
+			}
+		} else if err == nil {
+			t.Errorf("Want err != nil; got nil (i:%d)", i)
+		}
+	}
+}
+
+func TestTruncatedResponse(t *testing.T) {
+	for i, serveStale := range []bool{false, true} {
+		requests := 0
+		client := euroxref.New(4, 0)
+		client.(*euroxref.Client).ServeStale = serveStale
+		mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+			requests++
+			if requests > 1 {
+				data, _ := xml.Marshal(testResponse)
+				w.Write(data[:len(data)/2])
+				return
+			}
+			xmlHandle(testResponse)(w, req)
+		})
+		if _, err := client.Fetch(time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)); err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		lastFetched := client.LastFetched()
+		res, err := client.Fetch(time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC))
+		mock.Close()
+		if serveStale {
+			if err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			}
+			if len(res) != 3 {
+				t.Errorf("Want stale rates; got %v (i:%d)", res, i)
+			}
+		} else if err == nil {
+			t.Errorf("Want err != nil; got nil (i:%d)", i)
+		}
+		// Partially decoded data never replaces data held by the client.
+		if !client.Ready() {
+			t.Errorf("Want client to be ready (i:%d)", i)
+		}
+		if !client.LastFetched().Equal(lastFetched) {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", client.LastFetched(), lastFetched, i)
+		}
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("<html><body>Page not found</body></html>\n"))
+	})
+	defer mock.Close()
+	_, err := client.Fetch(time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC))
+	var sErr *euroxref.StatusError
+	if !errors.As(err, &sErr) {
+		t.Fatalf("Want *StatusError; got %v", err)
+	}
+	if sErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Values `%v` and `%v` are not equal", sErr.StatusCode, http.StatusNotFound)
+	}
+	if sErr.Body != "<html><body>Page not found</body></html>" {
+		t.Errorf("Values `%v` and `%v` are not equal", sErr.Body, "<html><body>Page not found</body></html>")
+	}
+	if !strings.Contains(err.Error(), "404 Not Found") || !strings.Contains(err.Error(), "Page not found") {
+		t.Errorf("Want error to contain status and body; got %v", err)
+	}
+	if client.(*euroxref.Client).XRefData != nil {
+		t.Errorf("Want no data to be stored for error response")
+	}
+}
@@ -0,0 +1,50 @@
+package euroxref
+
+// RoundingMode defines how values are rounded to requested precision.
+type RoundingMode int
+
+const (
+	// HalfUp rounds to the nearest value, ties away from zero.
+	HalfUp RoundingMode = iota
+	// HalfEven rounds to the nearest value, ties to the even digit (banker's rounding).
+	HalfEven
+	// Floor rounds towards negative infinity.
+	Floor
+	// Ceil rounds towards positive infinity.
+	Ceil
+	// Truncate rounds towards zero.
+	Truncate
+)
+
+// valid checks if m is one of defined rounding modes.
+func (m RoundingMode) valid() bool {
+	return m >= HalfUp && m <= Truncate
+}
+
+// WithRoundingMode sets rounding mode used for rates, amounts and conversion results, HalfUp by default.
+func WithRoundingMode(mode RoundingMode) Option {
+	return func(c *Client) {
+		c.roundingMode = mode
+	}
+}
+
+// FloatToFixedMode rounds floating number to prec digits (at least one) using given rounding mode.
+// Rounding is performed on decimal representation of the number, so e.g. 0.29 is never floored to 0.28.
+// Values too large to be represented with given precision (|num| * 10^prec >= 2^53) are returned unchanged.
+func FloatToFixedMode(num float64, prec int, mode RoundingMode) float64 {
+	// Force precision to be at least one
+	if prec < 1 {
+		prec = 1
+	}
+	// Past maxExactFloat float64 can't hold any more decimal digits than requested precision,
+	// rounding such values would only introduce noise so they're returned as is.
+	if exceedsFloatPrecision(num, prec) {
+		return num
+	}
+	exact, ok := ratFromFloat(num)
+	if !ok {
+		return num
+	}
+	res, _ := roundRat(exact, prec, mode).Float64()
+	return res
+}
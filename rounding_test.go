@@ -0,0 +1,97 @@
+package euroxref_test
+
+import (
+	"github.com/exaroth/euroxref-konrad"
+	"testing"
+	"time"
+)
+
+func TestFloatToFixedMode(t *testing.T) {
+	tests := []struct {
+		Value     float64
+		Precision int
+		Mode      euroxref.RoundingMode
+		Expected  float64
+	}{
+		{Value: 0.125, Precision: 2, Mode: euroxref.HalfUp, Expected: 0.13},
+		{Value: 0.125, Precision: 2, Mode: euroxref.HalfEven, Expected: 0.12},
+		{Value: 0.135, Precision: 2, Mode: euroxref.HalfEven, Expected: 0.14},
+		{Value: 0.125, Precision: 2, Mode: euroxref.Floor, Expected: 0.12},
+		{Value: 0.125, Precision: 2, Mode: euroxref.Ceil, Expected: 0.13},
+		{Value: 0.125, Precision: 2, Mode: euroxref.Truncate, Expected: 0.12},
+		{Value: -0.125, Precision: 2, Mode: euroxref.HalfUp, Expected: -0.13},
+		{Value: -0.125, Precision: 2, Mode: euroxref.HalfEven, Expected: -0.12},
+		{Value: -0.125, Precision: 2, Mode: euroxref.Floor, Expected: -0.13},
+		{Value: -0.125, Precision: 2, Mode: euroxref.Ceil, Expected: -0.12},
+		{Value: -0.125, Precision: 2, Mode: euroxref.Truncate, Expected: -0.12},
+		{Value: 2.5, Precision: 0, Mode: euroxref.HalfEven, Expected: 2.5}, // precision is always set to at least one
+		{Value: 0.25, Precision: 1, Mode: euroxref.HalfEven, Expected: 0.2},
+		{Value: 0.35, Precision: 1, Mode: euroxref.HalfEven, Expected: 0.4},
+		{Value: 0.29, Precision: 2, Mode: euroxref.Floor, Expected: 0.29},
+		{Value: 0.291, Precision: 2, Mode: euroxref.Floor, Expected: 0.29},
+		{Value: 0.291, Precision: 2, Mode: euroxref.Ceil, Expected: 0.3},
+		{Value: 1.005, Precision: 2, Mode: euroxref.HalfUp, Expected: 1.01},
+	}
+	for i, test := range tests {
+		res := euroxref.FloatToFixedMode(test.Value, test.Precision, test.Mode)
+		if res != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+}
+
+func TestRoundingMode(t *testing.T) {
+	tests := []struct {
+		Mode     euroxref.RoundingMode
+		Amount   float64
+		Expected float64
+	}{
+		// 10.25 EUR at 1.002 is exactly 10.2705 USD.
+		{Mode: euroxref.HalfUp, Amount: 10.25, Expected: 10.271},
+		{Mode: euroxref.HalfEven, Amount: 10.25, Expected: 10.27},
+		{Mode: euroxref.Floor, Amount: 10.25, Expected: 10.27},
+		{Mode: euroxref.Ceil, Amount: 10.25, Expected: 10.271},
+		{Mode: euroxref.Truncate, Amount: 10.25, Expected: 10.27},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(3, 0, euroxref.WithRoundingMode(test.Mode))
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		res, err := client.Convert(test.Amount, "EUR", "USD", time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if res != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+	if _, err := euroxref.NewValidated(4, 0, euroxref.WithRoundingMode(euroxref.RoundingMode(42))); err == nil {
+		t.Errorf("Want err != nil for unknown rounding mode; got nil")
+	}
+}
+
+func TestRoundingModeRates(t *testing.T) {
+	tests := []struct {
+		Mode     euroxref.RoundingMode
+		Expected float64
+	}{
+		{Mode: euroxref.HalfUp, Expected: 2},
+		{Mode: euroxref.Floor, Expected: 1.9999},
+	}
+	for i, test := range tests {
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 0, euroxref.WithRoundingMode(test.Mode), euroxref.WithRoundedRates())
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		defer mock.Close()
+		res, err := client.Rate("XYZ", time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		if res != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", res, test.Expected, i)
+		}
+	}
+}
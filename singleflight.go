@@ -0,0 +1,44 @@
+package euroxref
+
+import (
+	"context"
+)
+
+// fetchCall represents download of the data shared by concurrent callers.
+type fetchCall struct {
+	// Closed once download finishes.
+	done chan struct{}
+	err  error
+}
+
+// fetchShared retrieves data same as fetchData, concurrent callers wait for download already in progress
+// and share its result rather than requesting the data again.
+// Callers which stop waiting because their ctx is done return ctx.Err() without affecting the download.
+func (c *Client) fetchShared(ctx context.Context) error {
+	for {
+		c.flightMu.Lock()
+		call := c.flight
+		if call == nil {
+			call = &fetchCall{done: make(chan struct{})}
+			c.flight = call
+			c.flightMu.Unlock()
+			call.err = c.fetchData(ctx, false)
+			c.flightMu.Lock()
+			c.flight = nil
+			c.flightMu.Unlock()
+			close(call.done)
+			return call.err
+		}
+		c.flightMu.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		// Download aborted because ctx of the caller which started it was done doesn't concern other callers,
+		// who start a new one instead.
+		if call.err != context.Canceled && call.err != context.DeadlineExceeded {
+			return call.err
+		}
+	}
+}
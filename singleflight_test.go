@@ -0,0 +1,90 @@
+package euroxref_test
+
+import (
+	"context"
+	"github.com/exaroth/euroxref-konrad"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrentFetchSingleRequest(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		xmlHandle(testResponse)(w, req)
+	})
+	defer mock.Close()
+
+	var ready, wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		ready.Add(1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ready.Done()
+			_, err := client.Fetch(time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC))
+			errs <- err
+		}()
+	}
+	<-started
+	ready.Wait()
+	// Give remaining goroutines time to join the download in progress.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Want err == nil; got %v", err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Values `%v` and `%v` are not equal", requests, 1)
+	}
+}
+
+func TestConcurrentFetchCanceledWaiter(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		xmlHandle(testResponse)(w, req)
+	})
+	defer mock.Close()
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Fetch(date)
+		done <- err
+	}()
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.FetchContext(ctx, date); err != context.DeadlineExceeded {
+		t.Errorf("Want %v; got %v", context.DeadlineExceeded, err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("Want err == nil; got %v", err)
+	}
+}
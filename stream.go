@@ -0,0 +1,84 @@
+package euroxref
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"time"
+)
+
+// FetchStream retrieves exchange rates from SourceURL parsing records one by one as they're received
+// and calls handler for every day with its rates, in order of the feed which lists the latest days first.
+// Unlike other methods data is neither cached nor kept in memory, which keeps memory usage bounded
+// for large feeds such as full history of the rates.
+// Retrieval stops at first error returned by handler, which is returned unless it's ErrStopStream.
+func (c *Client) FetchStream(handler func(time.Time, ExchangeRates) error) error {
+	return c.FetchStreamContext(context.Background(), handler)
+}
+
+// FetchStreamContext streams exchange rates same as FetchStream, aborting retrieval of the data when ctx is done.
+func (c *Client) FetchStreamContext(ctx context.Context, handler func(time.Time, ExchangeRates) error) error {
+	c.mu.RLock()
+	closed := c.closed
+	c.mu.RUnlock()
+	if closed {
+		return ErrClientClosed
+	}
+	if c.SourceURL == "" {
+		return errors.New("Source url for exchange rate data is not set")
+	}
+	err := c.streamFeed(ctx, c.SourceURL, nil, func(dec *xml.Decoder) error {
+		return c.decodeDays(dec, handler)
+	})
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// decodeDays reads day records from dec one at a time and passes them parsed to handler.
+// Days without any rates are skipped.
+func (c *Client) decodeDays(dec *xml.Decoder, handler func(time.Time, ExchangeRates) error) error {
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "Cube" || !hasAttr(start, "time") {
+			continue
+		}
+		var record XRefRawData
+		if err = dec.DecodeElement(&record, &start); err != nil {
+			return err
+		}
+		if len(record.Rates) == 0 {
+			continue
+		}
+		t, err := time.Parse(XRefDateLayout, record.RateTime)
+		if err != nil {
+			return err
+		}
+		rates, err := c.parseRates(record.RateTime, record.Rates)
+		if err != nil {
+			return err
+		}
+		if err = handler(t, rates); err != nil {
+			return err
+		}
+	}
+}
+
+// hasAttr checks whether element has attribute with given name.
+func hasAttr(el xml.StartElement, name string) bool {
+	for _, attr := range el.Attr {
+		if attr.Name.Local == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package euroxref_test
+
+import (
+	"errors"
+	"github.com/exaroth/euroxref-konrad"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFetchStream(t *testing.T) {
+	client := euroxref.New(4, 0)
+	mock := MockServer(t, client.(*euroxref.Client), xmlHandle(testResponse))
+	defer mock.Close()
+
+	var dates []time.Time
+	err := client.FetchStream(func(date time.Time, rates euroxref.ExchangeRates) error {
+		dates = append(dates, date)
+		want, err := client.Fetch(date)
+		if err != nil {
+			t.Errorf("Want err == nil; got %v", err)
+		}
+		if !reflect.DeepEqual(rates, want) {
+			t.Errorf("Values `%v` and `%v` are not equal", rates, want)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	wantDates := []time.Time{
+		time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC),
+		time.Date(2016, time.November, 10, 0, 0, 0, 0, time.UTC),
+		time.Date(2016, time.November, 9, 0, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(dates, wantDates) {
+		t.Errorf("Values `%v` and `%v` are not equal", dates, wantDates)
+	}
+}
+
+func TestFetchStreamStop(t *testing.T) {
+	handlerErr := errors.New("Handler failed")
+	tests := []struct {
+		Err     error
+		WantErr error
+	}{
+		{
+			Err:     euroxref.ErrStopStream,
+			WantErr: nil,
+		},
+		{
+			Err:     handlerErr,
+			WantErr: handlerErr,
+		},
+	}
+	for i, test := range tests {
+		client := euroxref.New(4, 0)
+		mock := MockServer(t, client.(*euroxref.Client), xmlHandle(testResponse))
+		calls := 0
+		err := client.FetchStream(func(date time.Time, rates euroxref.ExchangeRates) error {
+			calls++
+			return test.Err
+		})
+		mock.Close()
+		if err != test.WantErr {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", err, test.WantErr, i)
+		}
+		if calls != 1 {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", calls, 1, i)
+		}
+		if client.(*euroxref.Client).XRefData != nil {
+			t.Errorf("Want streamed data not to be stored (i:%d)", i)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package euroxref
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"time"
+)
+
+// RateUpdate describes exchange rate data retrieved by the client which changed since it was last checked.
+type RateUpdate struct {
+	// Date of the most recent rates available.
+	LatestDate time.Time
+	// Rates published for LatestDate.
+	Rates ExchangeRates
+	// Time when data was retrieved.
+	Fetched time.Time
+}
+
+// Subscribe retrieves data once, then refreshes it every interval regardless of RefreshInterval
+// and sends update on returned channel whenever it changed, e.g. when ECB publishes rates for a new day
+// or revises already published ones. Failed refreshes are retried on the next tick.
+// Channel is closed when ctx is done or the client is closed. Error is returned if initial data
+// couldn't be retrieved, in which case no channel is returned.
+func (c *Client) Subscribe(ctx context.Context, interval time.Duration) (<-chan RateUpdate, error) {
+	if interval <= 0 {
+		return nil, errors.New("Interval of subscription has to be positive")
+	}
+	if err := c.fetchXML(ctx); err != nil {
+		return nil, err
+	}
+	_, lastHash, _ := c.rateUpdate()
+	updates := make(chan RateUpdate, 1)
+	go func() {
+		defer close(updates)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := c.Refresh(ctx); errors.Is(err, ErrClientClosed) {
+				return
+			}
+			update, hash, ok := c.rateUpdate()
+			if !ok || hash == lastHash {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case updates <- update:
+				lastHash = hash
+			}
+		}
+	}()
+	return updates, nil
+}
+
+// rateUpdate returns update describing data held by the client along with hash of the data,
+// ok is false if there's no data with any rates.
+func (c *Client) rateUpdate() (update RateUpdate, hash uint64, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.XRefData == nil {
+		return
+	}
+	record := c.latestRecord()
+	if record == nil {
+		return
+	}
+	date, err := time.Parse(XRefDateLayout, record.RateTime)
+	if err != nil {
+		return
+	}
+	rates, err := c.parseRecord(record)
+	if err != nil {
+		return
+	}
+	update = RateUpdate{LatestDate: date, Rates: rates, Fetched: c.lastFetched}
+	return update, dataHash(c.XRefData), true
+}
+
+// dataHash returns hash of all dates, currencies and rates contained in data.
+func dataHash(data *XRefRawResponse) uint64 {
+	h := fnv.New64a()
+	for _, dayD := range data.Data {
+		h.Write([]byte(dayD.RateTime))
+		for _, rec := range dayD.Rates {
+			h.Write([]byte{0})
+			h.Write([]byte(rec.Currency))
+			h.Write([]byte{0})
+			h.Write([]byte(rec.Rate))
+		}
+		h.Write([]byte{1})
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,138 @@
+package euroxref_test
+
+import (
+	"context"
+	"encoding/xml"
+	"github.com/exaroth/euroxref-konrad"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSubscribe(t *testing.T) {
+	var mu sync.Mutex
+	response := testResponse
+	client := euroxref.New(4, 3600)
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		xmlHandle(response)(w, req)
+	})
+	defer mock.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates, err := client.Subscribe(ctx, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	// Unchanged data isn't reported.
+	select {
+	case update := <-updates:
+		t.Fatalf("Want no update; got %v", update)
+	case <-time.After(50 * time.Millisecond):
+	}
+	mu.Lock()
+	response = &euroxref.XRefRawResponse{
+		Data: append([]euroxref.XRefRawData{
+			{
+				RateTime: "2016-11-14",
+				Rates:    []euroxref.RawExchangeRate{{Currency: "USD", Rate: "1.2"}},
+			},
+		}, testResponse.Data...),
+	}
+	mu.Unlock()
+	select {
+	case update := <-updates:
+		expected := euroxref.ExchangeRates{{Currency: "USD", Rate: 1.2}}
+		if date := time.Date(2016, time.November, 14, 0, 0, 0, 0, time.UTC); !update.LatestDate.Equal(date) {
+			t.Errorf("Values `%v` and `%v` are not equal", update.LatestDate, date)
+		}
+		if !reflect.DeepEqual(update.Rates, expected) {
+			t.Errorf("Values `%v` and `%v` are not equal", update.Rates, expected)
+		}
+		if update.Fetched.IsZero() {
+			t.Errorf("Want Fetched to be set")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Want update; got none")
+	}
+	cancel()
+	for range updates {
+	}
+}
+
+func TestSubscribeErrors(t *testing.T) {
+	client := euroxref.New(4, 3600)
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer mock.Close()
+	if _, err := client.Subscribe(context.Background(), 0); err == nil {
+		t.Errorf("Want err != nil; got nil")
+	}
+	if _, err := client.Subscribe(context.Background(), time.Millisecond); err == nil {
+		t.Errorf("Want err != nil; got nil")
+	}
+}
+
+func TestSubscribeClose(t *testing.T) {
+	client := euroxref.New(4, 3600)
+	mock := MockServer(t, client.(*euroxref.Client), xmlHandle(testResponse))
+	defer mock.Close()
+	updates, err := client.Subscribe(context.Background(), time.Millisecond)
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	client.Close()
+	select {
+	case _, ok := <-updates:
+		if ok {
+			t.Errorf("Want channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Want channel to be closed")
+	}
+}
+
+func TestSubscribeFailedRefresh(t *testing.T) {
+	var mu sync.Mutex
+	mode := "ok"
+	client := euroxref.New(4, 3600)
+	client.(*euroxref.Client).ServeStale = true
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch mode {
+		case "truncated":
+			data, _ := xml.Marshal(testResponse)
+			w.Write(data[:len(data)/2])
+		case "error":
+			http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		default:
+			xmlHandle(testResponse)(w, req)
+		}
+	})
+	defer mock.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates, err := client.Subscribe(ctx, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	// Neither failed nor partial refreshes, nor recovery to the same data, are reported.
+	for _, m := range []string{"truncated", "error", "ok"} {
+		mu.Lock()
+		mode = m
+		mu.Unlock()
+		select {
+		case update := <-updates:
+			t.Fatalf("Want no update; got %v (mode:%s)", update, m)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	cancel()
+	for range updates {
+	}
+}
@@ -0,0 +1,129 @@
+package euroxref
+
+import (
+	"context"
+	"encoding/xml"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultXMLCacheFile is name of the XML cache file used when it can't be derived from SourceURL.
+const defaultXMLCacheFile = "euroxref.xml"
+
+// xmlCachePath returns path of the file in CacheDir holding data retrieved from SourceURL.
+// Same as cacheKey, name of the file accounts for feeds merged into the data,
+// so that clients retrieving different data can share CacheDir.
+func (c *Client) xmlCachePath() string {
+	name := defaultXMLCacheFile
+	if u, err := url.Parse(c.SourceURL); err == nil {
+		if base := path.Base(u.Path); base != "." && base != "/" {
+			name = base
+		}
+	}
+	ext := path.Ext(name)
+	name = strings.TrimSuffix(name, ext)
+	if c.preferRevised {
+		name += "-revised"
+	}
+	if c.mergeDaily {
+		name += "-daily"
+	}
+	return filepath.Join(c.CacheDir, name+ext)
+}
+
+// cacheTTL returns period for which data stored in CacheDir is used instead of downloading it again.
+func (c *Client) cacheTTL() time.Duration {
+	if c.CacheTTL > 0 {
+		return c.CacheTTL
+	}
+	return time.Duration(c.RefreshInterval) * time.Second
+}
+
+// loadXMLCache populates client data from XML cache file if it's younger than cacheTTL.
+// Modification time of the file is used as the time data was retrieved.
+func (c *Client) loadXMLCache() (err error) {
+	p := c.xmlCachePath()
+	info, err := os.Stat(p)
+	if err != nil {
+		return
+	}
+	fetchedAt := info.ModTime()
+	if time.Since(fetchedAt) >= c.cacheTTL() {
+		return
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	data := &XRefRawResponse{}
+	err = xml.NewDecoder(f).Decode(data)
+	if err != nil {
+		return
+	}
+	dedupeDays(data)
+	c.XRefData = data
+	c.parsed = nil
+	c.lastFetched = fetchedAt
+	c.cachedUntil = fetchedAt.Add(c.cacheTTL())
+	return
+}
+
+// writeXMLCache persists currently retrieved data into XML cache file, replacing it atomically.
+func (c *Client) writeXMLCache() (err error) {
+	out, err := xml.Marshal(c.XRefData)
+	if err != nil {
+		return
+	}
+	p := c.xmlCachePath()
+	err = os.MkdirAll(c.CacheDir, 0755)
+	if err != nil {
+		return
+	}
+	f, err := os.CreateTemp(c.CacheDir, filepath.Base(p)+".tmp")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(append([]byte(xml.Header), out...))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return
+	}
+	err = os.Chtimes(f.Name(), c.lastFetched, c.lastFetched)
+	if err != nil {
+		return
+	}
+	return os.Rename(f.Name(), p)
+}
+
+// Refresh downloads exchange rate data regardless of RefreshInterval and any cached data.
+func (c *Client) Refresh(ctx context.Context) error {
+	return c.fetchData(ctx, true)
+}
+
+// InvalidateCache removes XML cache file from CacheDir and marks data held in memory as stale,
+// so that it's downloaded again on next use instead of being taken from Cache.
+func (c *Client) InvalidateCache() (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastFetched = time.Time{}
+	c.cachedUntil = time.Time{}
+	c.invalidated = true
+	// Data is downloaded in full rather than confirmed as unchanged.
+	c.validated = nil
+	if c.CacheDir == "" {
+		return
+	}
+	err = os.Remove(c.xmlCachePath())
+	if os.IsNotExist(err) {
+		err = nil
+	}
+	return
+}
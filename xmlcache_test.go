@@ -0,0 +1,164 @@
+package euroxref_test
+
+import (
+	"context"
+	"github.com/exaroth/euroxref-konrad"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestXMLCache(t *testing.T) {
+	date := time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC)
+	tests := []struct {
+		RefreshInterval uint
+		CacheTTL        time.Duration
+		Age             time.Duration
+		Requests        int
+	}{
+		{
+			RefreshInterval: 60,
+			Requests:        0,
+		},
+		{
+			RefreshInterval: 0,
+			CacheTTL:        time.Hour,
+			Requests:        0,
+		},
+		{
+			RefreshInterval: 0,
+			CacheTTL:        time.Hour,
+			Age:             2 * time.Hour,
+			Requests:        2,
+		},
+		{
+			RefreshInterval: 0,
+			Requests:        2,
+		},
+	}
+	for i, test := range tests {
+		dir := t.TempDir()
+		var reqUrl, reqMethod, reqBody string
+		handler := testHandle(&reqUrl, &reqMethod, &reqBody)
+		client := euroxref.New(4, 60)
+		client.(*euroxref.Client).CacheDir = dir
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		expected, err := client.Fetch(date)
+		mock.Close()
+		if err != nil {
+			t.Fatalf("Want err == nil; got %v (i:%d)", err, i)
+		}
+		path := filepath.Join(dir, "eurofxref-hist-90d.xml")
+		if test.Age > 0 {
+			past := time.Now().Add(-test.Age)
+			if err := os.Chtimes(path, past, past); err != nil {
+				t.Fatalf("Want err == nil; got %v (i:%d)", err, i)
+			}
+		}
+
+		// New client, e.g. in the next run of the process, should reuse the cached file while it's fresh.
+		requests := 0
+		client = euroxref.New(4, test.RefreshInterval)
+		client.(*euroxref.Client).CacheDir = dir
+		client.(*euroxref.Client).CacheTTL = test.CacheTTL
+		mock = MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+			requests++
+			xmlHandle(testResponse)(w, req)
+		})
+		for j := 0; j < 2; j++ {
+			res, err := client.Fetch(date)
+			if err != nil {
+				t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			}
+			if !reflect.DeepEqual(expected, res) {
+				t.Errorf("Values `%v` and `%v` are not equal (i:%d)", expected, res, i)
+			}
+		}
+		mock.Close()
+		if requests != test.Requests {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", requests, test.Requests, i)
+		}
+	}
+}
+
+func TestRefreshAndInvalidateCache(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "eurofxref-hist-90d.xml")
+	date := time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC)
+	requests := 0
+	client := euroxref.New(4, 60)
+	client.(*euroxref.Client).CacheDir = dir
+	mock := MockServer(t, client.(*euroxref.Client), func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		xmlHandle(testResponse)(w, req)
+	})
+	defer mock.Close()
+	if _, err := client.Fetch(date); err != nil {
+		t.Fatalf("Want err == nil; got %v", err)
+	}
+	if err := client.Refresh(context.Background()); err != nil {
+		t.Errorf("Want err == nil; got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Values `%v` and `%v` are not equal", requests, 2)
+	}
+	if err := client.InvalidateCache(); err != nil {
+		t.Errorf("Want err == nil; got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Want cache file to be removed; got %v", err)
+	}
+	if _, err := client.Fetch(date); err != nil {
+		t.Errorf("Want err == nil; got %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("Values `%v` and `%v` are not equal", requests, 3)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Want cache file to be written again; got %v", err)
+	}
+}
+
+func TestXMLCacheFeeds(t *testing.T) {
+	dir := t.TempDir()
+	date := time.Date(2016, time.November, 11, 0, 0, 0, 0, time.UTC)
+	handler := routeHandle(map[string]*euroxref.XRefRawResponse{
+		"/stats/eurofxref/eurofxref-hist-90d.xml": testResponse,
+		"/stats/eurofxref/eurofxref-hist.xml":     revisedResponse,
+	})
+	tests := []struct {
+		Options  []euroxref.Option
+		Expected float64
+	}{
+		{
+			Options:  nil,
+			Expected: 1.002,
+		},
+		{
+			Options:  []euroxref.Option{euroxref.WithRevisedRates()},
+			Expected: 1.1,
+		},
+		{
+			Options:  nil,
+			Expected: 1.002,
+		},
+	}
+	// Clients retrieving different data share CacheDir, each of them has to be served its own data.
+	for i, test := range tests {
+		client := euroxref.New(4, 60, test.Options...)
+		client.(*euroxref.Client).CacheDir = dir
+		mock := MockServer(t, client.(*euroxref.Client), handler)
+		rates, err := client.Fetch(date)
+		mock.Close()
+		if err != nil {
+			t.Errorf("Want err == nil; got %v (i:%d)", err, i)
+			continue
+		}
+		if rate := rates.Map()["USD"]; rate != test.Expected {
+			t.Errorf("Values `%v` and `%v` are not equal (i:%d)", test.Expected, rate, i)
+		}
+	}
+}